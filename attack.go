@@ -0,0 +1,110 @@
+package dice
+
+import "math/big"
+
+//CritRule controls how damage is increased by a critical hit.
+type CritRule int
+
+const (
+	//CritDoubleDice rolls the damage dice twice, modifiers are only applied once.
+	CritDoubleDice CritRule = iota
+	//CritMultiplyDamage multiplies the rolled damage by the attack's CritMultiplier.
+	CritMultiplyDamage
+)
+
+//Attack describes an attack roll against a target along with the damage it deals.
+type Attack struct {
+	ToHit          string   //roll expression for the attack, e.g. 1d20+7
+	Against        int      //value the attack must beat, e.g. armor class
+	EqualSucceeds  bool     //hit when the attack roll equals Against, like RollChallenge
+	CritRange      int      //natural rolls at or above this always hit and crit, 0 disables crits
+	MissRange      int      //natural rolls at or below this always miss, 0 disables automatic misses
+	CritRule       CritRule //how critical hits increase damage
+	CritMultiplier int      //used by CritMultiplyDamage, defaults to 2
+	Damage         string   //roll expression for the damage dealt on a hit, e.g. 2d6+4
+}
+
+//AttackAnalysis holds the chances of an attack hitting and the damage it is expected to deal.
+type AttackAnalysis struct {
+	Hit            *big.Rat //chance of hitting, including critical hits
+	Crit           *big.Rat //chance of a critical hit
+	Miss           *big.Rat //chance of missing
+	ExpectedDamage *big.Rat //average damage per attack, misses included
+	Exact          bool     //false when the damage had to be estimated by simulation
+}
+
+//AnalyzeAttack calculates the chance of an attack hitting, critically hitting, and its expected damage.
+//A natural roll is the result of the attack's first dice before any modifiers, so for 1d20+7 it is the d20.
+//
+//An error is returned if either expression is not a valid roll expression, the crit or miss range
+//is negative, or the attack roll is too large to compute exactly.
+func AnalyzeAttack(attack Attack) (AttackAnalysis, error) {
+	toHit, err := parseExpression(attack.ToHit)
+	if err != nil {
+		return AttackAnalysis{}, err
+	}
+
+	damage, err := parseExpression(attack.Damage)
+	if err != nil {
+		return AttackAnalysis{}, err
+	}
+
+	if attack.CritRange < 0 || attack.MissRange < 0 {
+		return AttackAnalysis{}, ErrInvalidRange
+	}
+
+	//make sure the attack roll can be computed exactly before working through each natural roll
+	if _, err := toHit.exactDistribution(); err != nil {
+		return AttackAnalysis{}, err
+	}
+
+	highestMiss := attack.Against
+	if attack.EqualSucceeds {
+		highestMiss = attack.Against - 1
+	}
+
+	naturals := toHit.naturalDistribution()
+	hit, crit := new(big.Rat), new(big.Rat)
+	for _, natural := range naturals.Outcomes() {
+		chance := naturals.ExactProbability(natural)
+		switch {
+		case attack.CritRange > 0 && natural >= attack.CritRange:
+			crit.Add(crit, chance)
+		case attack.MissRange > 0 && natural <= attack.MissRange:
+			continue
+		default:
+			totals := toHit.finish(pointDistribution(natural))
+			hits := new(big.Rat).Sub(big.NewRat(1, 1), totals.ExactCumulative(highestMiss))
+			hit.Add(hit, hits.Mul(hits, chance))
+		}
+	}
+
+	normal := damage.distribution()
+	var critical *Distribution
+	switch attack.CritRule {
+	case CritMultiplyDamage:
+		multiplier := attack.CritMultiplier
+		if multiplier == 0 {
+			multiplier = 2
+		}
+		critical = normal.transform(func(v int) int { return v * multiplier })
+	default:
+		doubled := damage
+		doubled.number *= 2
+		doubled.secondNumber *= 2
+		critical = doubled.distribution()
+	}
+
+	expected := new(big.Rat).Mul(hit, normal.ExactMean())
+	expected.Add(expected, new(big.Rat).Mul(crit, critical.ExactMean()))
+
+	hit.Add(hit, crit)
+
+	return AttackAnalysis{
+		Hit:            hit,
+		Crit:           crit,
+		Miss:           new(big.Rat).Sub(big.NewRat(1, 1), hit),
+		ExpectedDamage: expected,
+		Exact:          normal.Exact() && critical.Exact(),
+	}, nil
+}
@@ -0,0 +1,99 @@
+package dice
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func Test_AnalyzeAttack(t *testing.T) {
+	testCases := []struct {
+		attack   Attack
+		hit      *big.Rat
+		crit     *big.Rat
+		expected *big.Rat
+		err      error
+	}{
+		{
+			attack:   Attack{ToHit: "1d20+7", Against: 15, EqualSucceeds: true, CritRange: 19, MissRange: 1, Damage: "2d6+4"},
+			hit:      big.NewRat(13, 20),
+			crit:     big.NewRat(2, 20),
+			expected: big.NewRat(157, 20),
+		},
+		{
+			attack:   Attack{ToHit: "1d20+7", Against: 15, EqualSucceeds: true, CritRange: 19, MissRange: 1, CritRule: CritMultiplyDamage, Damage: "2d6+4"},
+			hit:      big.NewRat(13, 20),
+			crit:     big.NewRat(2, 20),
+			expected: big.NewRat(165, 20),
+		},
+		{
+			attack:   Attack{ToHit: "1d20+7", Against: 15, CritRange: 20, CritRule: CritMultiplyDamage, CritMultiplier: 3, Damage: "1d8"},
+			hit:      big.NewRat(12, 20),
+			crit:     big.NewRat(1, 20),
+			expected: new(big.Rat).Add(big.NewRat(11*9, 40), big.NewRat(27, 40)),
+		},
+		{
+			attack:   Attack{ToHit: "1d20+7", Against: 30, CritRange: 20, Damage: "1d6"},
+			hit:      big.NewRat(1, 20),
+			crit:     big.NewRat(1, 20),
+			expected: big.NewRat(7, 20),
+		},
+		{
+			attack:   Attack{ToHit: "1d20+1d4", Against: 20, Damage: "1d4"},
+			hit:      big.NewRat(10, 80),
+			crit:     big.NewRat(0, 1),
+			expected: big.NewRat(25, 80),
+		},
+		{
+			attack:   Attack{ToHit: "max:2d20+5", Against: 20, CritRange: 20, Damage: "1d10"},
+			hit:      big.NewRat(400-15*15, 400),
+			crit:     big.NewRat(39, 400),
+			expected: new(big.Rat).Add(big.NewRat((400-225-39)*11, 800), big.NewRat(39*11, 400)),
+		},
+		{
+			attack: Attack{ToHit: "heyo", Damage: "1d6"},
+			err:    ErrInvalidRollExpression,
+		},
+		{
+			attack: Attack{ToHit: "1d20", Damage: "heyo"},
+			err:    ErrInvalidRollExpression,
+		},
+		{
+			attack: Attack{ToHit: "1d20", CritRange: -1, Damage: "1d6"},
+			err:    ErrInvalidRange,
+		},
+		{
+			attack: Attack{ToHit: "dropL:dropH:100d100", Damage: "1d6"},
+			err:    ErrDistributionTooLarge,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s vs %d", i, tc.attack.ToHit, tc.attack.Against), func(t *testing.T) {
+			got, err := AnalyzeAttack(tc.attack)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if !got.Exact {
+				t.Error("[exact] want true, got false")
+			}
+			if got.Hit.Cmp(tc.hit) != 0 {
+				t.Errorf("[hit] want %s, got %s", tc.hit.RatString(), got.Hit.RatString())
+			}
+			if got.Crit.Cmp(tc.crit) != 0 {
+				t.Errorf("[crit] want %s, got %s", tc.crit.RatString(), got.Crit.RatString())
+			}
+			miss := new(big.Rat).Sub(big.NewRat(1, 1), tc.hit)
+			if got.Miss.Cmp(miss) != 0 {
+				t.Errorf("[miss] want %s, got %s", miss.RatString(), got.Miss.RatString())
+			}
+			if got.ExpectedDamage.Cmp(tc.expected) != 0 {
+				t.Errorf("[expected damage] want %s, got %s", tc.expected.RatString(), got.ExpectedDamage.RatString())
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package dice
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//ParseError is returned when a line of a dice bag cannot be imported.
+type ParseError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %q: %s", e.Line, e.Text, e.Err)
+}
+
+//Unwrap returns the reason the line could not be imported.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+//bagLine is a dice read from a dice bag along with the line it was read from.
+type bagLine struct {
+	line  int
+	text  string
+	entry Entry
+}
+
+//WriteText writes the dice in the set to w as a plain-text dice bag, one dice per line sorted by name,
+//e.g. "fireball: 8d6 # DEX save". A description is written as a comment after the expression.
+//Dice inherited from a parent are not written.
+//
+//An error is returned if a dice cannot be written on a single line that reads back the same, or writing fails.
+func (s *Set) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, entry := range s.load().sorted() {
+		if entry.Name == "" || entry.Name != strings.TrimSpace(entry.Name) || strings.ContainsAny(entry.Name, "#\r\n") ||
+			strings.ContainsAny(entry.Description, "\r\n") {
+			return fmt.Errorf("%q: %w", entry.Name, ErrNotWritableAsText)
+		}
+
+		line := fmt.Sprintf("%s: %s", entry.Name, entry.Expression)
+		if entry.Description != "" {
+			line += " # " + strings.TrimSpace(entry.Description)
+		}
+		if name, expression, _, ok := splitBagLine(line); !ok || name != entry.Name || expression != entry.Expression {
+			return fmt.Errorf("%q: %w", entry.Name, ErrNotWritableAsText)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+//ReadText adds the dice in a plain-text dice bag to the set, replacing any dice with the same name. Every line holds
+//a name and an expression separated by a colon, optionally followed by a comment that becomes the description of the
+//dice. Blank lines and lines starting with # are ignored.
+//
+//	# spells
+//	fireball: 8d6 # DEX save
+//	magic missile: 3d4+3
+//
+//A *ParseError with the line number is returned for the first line that cannot be imported, the set is only changed
+//if every line can be.
+func (s *Set) ReadText(r io.Reader) error {
+	var lines []bagLine
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text := scanner.Text()
+		content := strings.TrimSpace(text)
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+
+		name, expression, description, ok := splitBagLine(content)
+		if !ok {
+			return &ParseError{Line: number, Text: text, Err: ErrInvalidDiceLine}
+		}
+
+		lines = append(lines, bagLine{line: number, text: text, entry: Entry{Name: name, Expression: expression, Description: description}})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return s.importLines(lines)
+}
+
+//splitBagLine splits a dice bag line into its name, expression, and description. The description follows the first #
+//outside of braces, and the expression follows the first colon outside of braces that is followed by a valid
+//expression, so names and expressions can both hold colons (e.g. "spells: fireball: 8d6" or "x: @{a:b}+1"). When no
+//colon is followed by a valid expression the last one is used, leaving the expression to be reported as invalid.
+func splitBagLine(content string) (name string, expression string, description string, ok bool) {
+	var colons []int
+	inBraces := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '{':
+			inBraces = true
+		case c == '}':
+			inBraces = false
+		case c == ':' && !inBraces:
+			colons = append(colons, i)
+		case c == '#' && !inBraces:
+			content, description = content[:i], strings.TrimSpace(content[i+1:])
+		}
+	}
+	if len(colons) == 0 {
+		return "", "", "", false
+	}
+
+	split := colons[len(colons)-1]
+	for _, i := range colons {
+		if _, err := parseSetExpression(strings.TrimSpace(content[i+1:])); err == nil {
+			split = i
+			break
+		}
+	}
+	name, expression = strings.TrimSpace(content[:split]), strings.TrimSpace(content[split+1:])
+
+	return name, expression, description, name != "" && expression != ""
+}
+
+//ReadCSV adds the dice in CSV data to the set, replacing any dice with the same name. Every record holds a name and
+//an expression, the same shape returned by ListDice. A name containing commas does not need to be quoted since the
+//expression is always the last field. A "name,expression" header, blank lines, and lines starting with # are ignored.
+//
+//A *ParseError with the line number is returned for the first record that cannot be imported, the set is only changed
+//if every record can be.
+func (s *Set) ReadCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	var lines []bagLine
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var csvErr *csv.ParseError
+		if errors.As(err, &csvErr) {
+			return &ParseError{Line: csvErr.Line, Err: csvErr.Err}
+		}
+		if err != nil {
+			return err
+		}
+
+		number, _ := reader.FieldPos(0)
+		text := strings.Join(record, ",")
+		if len(lines) == 0 && strings.EqualFold(text, "name,expression") {
+			continue
+		}
+
+		if len(record) < 2 {
+			return &ParseError{Line: number, Text: text, Err: ErrInvalidDiceLine}
+		}
+		name := strings.TrimSpace(strings.Join(record[:len(record)-1], ","))
+		expression := strings.TrimSpace(record[len(record)-1])
+		if name == "" || expression == "" {
+			return &ParseError{Line: number, Text: text, Err: ErrInvalidDiceLine}
+		}
+
+		lines = append(lines, bagLine{line: number, text: text, entry: Entry{Name: name, Expression: expression}})
+	}
+
+	return s.importLines(lines)
+}
+
+//importLines adds every dice to the set, or none of them if any cannot be added. A dice can reference
+//dice on later lines, references are only checked once every line has been added.
+func (s *Set) importLines(lines []bagLine) error {
+	return s.update(func(next *setState) ([]Event, error) {
+		var events []Event
+		imported := make(map[string]bool)
+		for _, line := range lines {
+			entry := line.entry
+			entry.Name = next.canonical(entry.Name)
+
+			_, err := parseSetExpression(entry.Expression)
+			if err == nil {
+				err = next.checkReferences(entry.Name, entry.Expression)
+			}
+			if imported[entry.Name] {
+				err = ErrDuplicateDice
+			}
+			if err != nil {
+				return nil, &ParseError{Line: line.line, Text: line.text, Err: err}
+			}
+
+			//an existing dice keeps everything but its expression, and its description unless one is given
+			existing, exists := next.dice[entry.Name]
+			if exists {
+				description := entry.Description
+				entry = existing
+				entry.Expression = line.entry.Expression
+				if description != "" {
+					entry.Description = description
+				}
+			}
+			events = append(events, putEvent(next.put(entry), existing, exists))
+			imported[entry.Name] = true
+		}
+
+		for _, line := range lines {
+			if err := next.checkResolved(next.canonical(line.entry.Name), line.entry.Expression); err != nil {
+				return nil, &ParseError{Line: line.line, Text: line.text, Err: err}
+			}
+		}
+
+		return events, nil
+	})
+}
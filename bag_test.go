@@ -0,0 +1,201 @@
+package dice
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSet_ReadText(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		bag := `# spells
+fireball: 8d6 # DEX save
+
+  magic missile : 3d4+3
+attack: @{magic missile}+1
+`
+		subject := &Set{}
+		if err := subject.ReadText(strings.NewReader(bag)); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		assertEntries(t, subject, map[string]string{"fireball": "8d6", "magic missile": "3d4+3", "attack": "@{magic missile}+1"})
+		if got := subject.load().dice["fireball"].Description; got != "DEX save" {
+			t.Errorf("[description] want DEX save, got %s", got)
+		}
+	})
+
+	t.Run("existing dice keep their information", func(t *testing.T) {
+		rules := CallOfCthulhuRules()
+		subject := &Set{}
+		_ = subject.AddEntry(Entry{Name: "spot", Expression: "1d100", Description: "Spot Hidden", Tags: []string{"skill"}, Category: "skills", Icon: "eye", RollUnder: &rules})
+		before, _ := subject.Get("spot")
+
+		if err := subject.ReadText(strings.NewReader("spot: 1d100+0")); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		got, _ := subject.Get("spot")
+		want := before
+		want.Expression, want.Updated = "1d100+0", got.Updated
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("dice can reference later lines", func(t *testing.T) {
+		subject := &Set{}
+		if err := subject.ReadText(strings.NewReader("attack: @rapier+1\nrapier: 1d8")); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		assertEntries(t, subject, map[string]string{"attack": "@rapier+1", "rapier": "1d8"})
+	})
+
+	t.Run("colons and hashes in braces", func(t *testing.T) {
+		bag := "spells: fireball: 8d6 # DEX: save\na:b: 1d4\nx: @{a:b}+@{a#b}+1 # both"
+		subject := NewSet(map[string]string{"a#b": "1d4"})
+		if err := subject.ReadText(strings.NewReader(bag)); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		assertEntries(t, subject, map[string]string{"spells: fireball": "8d6", "a:b": "1d4", "a#b": "1d4", "x": "@{a:b}+@{a#b}+1"})
+		if got := subject.load().dice["spells: fireball"].Description; got != "DEX: save" {
+			t.Errorf("[description] want DEX: save, got %s", got)
+		}
+	})
+
+	t.Run("errors include the line", func(t *testing.T) {
+		tests := map[string]struct {
+			bag      string
+			wantLine int
+			wantErr  error
+		}{
+			"missing colon":      {bag: "fireball: 8d6\n\nmagic missile 3d4", wantLine: 3, wantErr: ErrInvalidDiceLine},
+			"missing expression": {bag: "fireball:", wantLine: 1, wantErr: ErrInvalidDiceLine},
+			"invalid expression": {bag: "# spells\nfireball: 8d", wantLine: 2, wantErr: ErrInvalidRollExpression},
+			"duplicate":          {bag: "fireball: 8d6\nfireball: 6d6", wantLine: 2, wantErr: ErrDuplicateDice},
+			"cycle":              {bag: "a: @b\nb: @a", wantLine: 2, wantErr: ErrCyclicReference},
+			"missing reference":  {bag: "a: 1d6\nb: @c+@a", wantLine: 2, wantErr: ErrMissingReference},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				subject := NewSet(map[string]string{"d6": "1d6"})
+				err := subject.ReadText(strings.NewReader(test.bag))
+
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) || parseErr.Line != test.wantLine || !errors.Is(err, test.wantErr) {
+					t.Errorf("want error %s on line %d, got %v", test.wantErr, test.wantLine, err)
+				}
+				assertEntries(t, subject, map[string]string{"d6": "1d6"})
+			})
+		}
+	})
+}
+
+func TestSet_WriteText(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		original := NewSet(map[string]string{"magic missile": "3d4+3", "attack": "@{magic missile}+1", "a:b": "1d4", "x": "@{a:b}+1"})
+		_ = original.AddEntry(Entry{Name: "fireball", Expression: "8d6", Description: "DEX save"})
+
+		var buf bytes.Buffer
+		if err := original.WriteText(&buf); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		want := "a:b: 1d4\nattack: @{magic missile}+1\nfireball: 8d6 # DEX save\nmagic missile: 3d4+3\nx: @{a:b}+1\n"
+		if buf.String() != want {
+			t.Errorf("want %q, got %q", want, buf.String())
+		}
+
+		loaded := &Set{}
+		if err := loaded.ReadText(&buf); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if !reflect.DeepEqual(loaded.ListDice(), original.ListDice()) {
+			t.Errorf("want %s, got %s", original.ListDice(), loaded.ListDice())
+		}
+		if got := loaded.load().dice["fireball"].Description; got != "DEX save" {
+			t.Errorf("[description] want DEX save, got %s", got)
+		}
+	})
+
+	t.Run("error when name cannot be written", func(t *testing.T) {
+		subject := NewSet(map[string]string{"#1": "1d6"})
+		if err := subject.WriteText(&bytes.Buffer{}); !errors.Is(err, ErrNotWritableAsText) {
+			t.Errorf("want error %s, got %v", ErrNotWritableAsText, err)
+		}
+	})
+
+	t.Run("error when dice cannot be read back", func(t *testing.T) {
+		subject := NewSet(map[string]string{"a{": "1d6"})
+		if err := subject.WriteText(&bytes.Buffer{}); !errors.Is(err, ErrNotWritableAsText) {
+			t.Errorf("want error %s, got %v", ErrNotWritableAsText, err)
+		}
+	})
+
+	t.Run("error when writing fails", func(t *testing.T) {
+		subject := NewSet(map[string]string{"d6": "1d6"})
+		if err := subject.WriteText(failingWriter{}); err != errWriteFailed {
+			t.Errorf("want error %s, got %v", errWriteFailed, err)
+		}
+	})
+}
+
+func TestSet_ReadCSV(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		original := NewSet(map[string]string{"dagger, offhand": "1d4", "main weapon": "1d20+3"})
+
+		subject := &Set{}
+		data := "name,expression\n" + strings.Join(original.ListDice(), "\n") + "\n"
+		if err := subject.ReadCSV(strings.NewReader(data)); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		assertEntries(t, subject, map[string]string{"dagger, offhand": "1d4", "main weapon": "1d20+3"})
+	})
+
+	t.Run("existing dice keep their information", func(t *testing.T) {
+		rules := CallOfCthulhuRules()
+		subject := &Set{}
+		_ = subject.AddEntry(Entry{Name: "spot", Expression: "1d100", Description: "Spot Hidden", Tags: []string{"skill"}, RollUnder: &rules})
+
+		if err := subject.ReadCSV(strings.NewReader("spot,1d100+0")); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		got, _ := subject.Get("spot")
+		if got.Expression != "1d100+0" || got.Description != "Spot Hidden" || got.RollUnder == nil || *got.RollUnder != rules {
+			t.Errorf("entry information was not kept, got %+v", got)
+		}
+	})
+
+	t.Run("errors include the line", func(t *testing.T) {
+		tests := map[string]struct {
+			data     string
+			wantLine int
+			wantErr  error
+		}{
+			"missing expression": {data: "fireball,8d6\n\nmagic missile", wantLine: 3, wantErr: ErrInvalidDiceLine},
+			"invalid expression": {data: "# spells\nfireball,8d", wantLine: 2, wantErr: ErrInvalidRollExpression},
+			"bad quoting":        {data: "fireball,8d6\n\"magic\"missile\",3d4", wantLine: 2, wantErr: nil},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				subject := &Set{}
+				err := subject.ReadCSV(strings.NewReader(test.data))
+
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) || parseErr.Line != test.wantLine || test.wantErr != nil && !errors.Is(err, test.wantErr) {
+					t.Errorf("want error %v on line %d, got %v", test.wantErr, test.wantLine, err)
+				}
+				if len(subject.load().dice) != 0 {
+					t.Errorf("want no dice, got %v", subject.ListDice())
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package dice
+
+import "time"
+
+//Participant is one roller in a batch challenge, e.g. a monster making a saving throw.
+type Participant struct {
+	Name       string
+	Expression string
+	Target     int
+}
+
+//ParticipantResult is the result of one participant's challenge.
+type ParticipantResult struct {
+	Name   string
+	Result RollChallengeResult
+	Err    error //why the participant could not roll, the rest of the batch is still rolled
+}
+
+//BatchChallengeResult holds the result of every participant in a batch challenge.
+type BatchChallengeResult struct {
+	Results   []ParticipantResult //in the same order as the participants
+	Succeeded int                 //number of participants that succeeded
+	Failed    []string            //names of participants that rolled and failed, in order
+	Seed      int64               //seed used, pass it back in the options to replay the batch
+}
+
+//RollBatchChallenge rolls a challenge for every participant against their own target, judged by the same options.
+//Each participant rolls from their own stream seeded from the options' seed and their position, so the same seed and
+//participants always replay the same results. A seed of zero picks a random seed, which is returned with the results.
+//
+//Participants whose expression is not a valid roll expression have their error reported in their result
+//and are not counted as succeeding or failing.
+func RollBatchChallenge(participants []Participant, opts ChallengeOptions) BatchChallengeResult {
+	batch := BatchChallengeResult{Seed: opts.Seed, Results: make([]ParticipantResult, 0, len(participants))}
+	if batch.Seed == 0 {
+		batch.Seed = time.Now().UnixNano()
+	}
+
+	for i, participant := range participants {
+		result := ParticipantResult{Name: participant.Name}
+		parsed, err := parseExpression(participant.Expression)
+		if err != nil {
+			result.Err = err
+			batch.Results = append(batch.Results, result)
+			continue
+		}
+
+		result.Result = rollChallenge(participant.Expression, parsed, participant.Target, opts, New(chunkSeed(batch.Seed, i)))
+		if result.Result.Success {
+			batch.Succeeded++
+		} else {
+			batch.Failed = append(batch.Failed, participant.Name)
+		}
+		batch.Results = append(batch.Results, result)
+	}
+
+	return batch
+}
@@ -0,0 +1,70 @@
+package dice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRollBatchChallenge(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		participants := []Participant{
+			{Name: "goblin", Expression: "1d1+2", Target: 3},
+			{Name: "ogre", Expression: "1d1+5", Target: 3},
+			{Name: "wisp", Expression: "1d", Target: 3},
+			{Name: "troll", Expression: "1d1+3", Target: 3},
+		}
+
+		got := RollBatchChallenge(participants, ChallengeOptions{EqualSucceeds: true})
+		if got.Succeeded != 3 {
+			t.Errorf("[succeeded] want 3, got %d", got.Succeeded)
+		}
+		if !reflect.DeepEqual(got.Failed, []string(nil)) {
+			t.Errorf("[failed] want none, got %v", got.Failed)
+		}
+		if got.Seed == 0 {
+			t.Errorf("[seed] want the seed used, got 0")
+		}
+
+		if len(got.Results) != len(participants) {
+			t.Fatalf("[len] want %d, got %d", len(participants), len(got.Results))
+		}
+		for i, result := range got.Results {
+			if result.Name != participants[i].Name {
+				t.Errorf("[%d] want %s, got %s", i, participants[i].Name, result.Name)
+			}
+		}
+		if got.Results[2].Err != ErrInvalidRollExpression {
+			t.Errorf("[wisp] want error %s, got %v", ErrInvalidRollExpression, got.Results[2].Err)
+		}
+		if got.Results[1].Result.Total != 6 || got.Results[1].Result.Target != 3 {
+			t.Errorf("[ogre] want 6 against 3, got %d against %d", got.Results[1].Result.Total, got.Results[1].Result.Target)
+		}
+	})
+
+	t.Run("failures are listed", func(t *testing.T) {
+		participants := []Participant{
+			{Name: "goblin", Expression: "1d1+2", Target: 3},
+			{Name: "ogre", Expression: "1d1+5", Target: 3},
+			{Name: "kobold", Expression: "1d1", Target: 3},
+		}
+
+		got := RollBatchChallenge(participants, ChallengeOptions{})
+		if got.Succeeded != 1 || !reflect.DeepEqual(got.Failed, []string{"goblin", "kobold"}) {
+			t.Errorf("want 1 success and goblin, kobold failed, got %d and %v", got.Succeeded, got.Failed)
+		}
+	})
+
+	t.Run("seed replays results", func(t *testing.T) {
+		participants := []Participant{
+			{Name: "goblin", Expression: "1d20+2", Target: 12},
+			{Name: "ogre", Expression: "1d20+5", Target: 14},
+			{Name: "troll", Expression: "2d6+3", Target: 10},
+		}
+
+		first := RollBatchChallenge(participants, ChallengeOptions{})
+		replayed := RollBatchChallenge(participants, ChallengeOptions{Seed: first.Seed})
+		if !reflect.DeepEqual(first, replayed) {
+			t.Errorf("want %+v, got %+v", first, replayed)
+		}
+	})
+}
@@ -1,34 +1,278 @@
 package dice
 
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+//ChallengeMode selects whether a challenge is won by rolling over or under its target.
+type ChallengeMode int
+
+const (
+	//ChallengeOver succeeds when the total beats the target, or equals it if EqualSucceeds is set.
+	ChallengeOver ChallengeMode = iota
+	//ChallengeUnder succeeds when the total is at or under the target, graded by the RollUnder rules.
+	ChallengeUnder
+)
+
+//ChallengeOptions controls how a challenge is rolled and judged. The zero value is a plain roll-over challenge.
+type ChallengeOptions struct {
+	Mode          ChallengeMode
+	EqualSucceeds bool           //roll-over challenges also succeed when the total equals the target
+	AlertOn       []int          //dice values to report when rolled
+	Natural       NaturalRules   //natural rolls that always succeed or fail
+	Degrees       *DegreeRules   //grade roll-over challenges by degree of success, meeting the target succeeds
+	RollUnder     RollUnderRules //grading and fumbles for roll-under challenges
+	Seed          int64          //the same seed always rolls the same results, 0 rolls randomly
+}
+
+//RollChallengeResult holds everything about a rolled challenge.
+type RollChallengeResult struct {
+	Success           bool
+	Rolls             []int
+	Natural           int //the first dice before modifiers, e.g. the d20 of 1d20+7
+	Total             int
+	Target            int
+	Margin            int   //how far the roll beat the target by, zero when it met the target, negative when it fell short
+	Alerts            []int //the AlertOn values that were rolled, once for every dice that rolled one
+	Critical          bool  //the natural roll was a critical, confirmed if the rules require it
+	Threat            bool  //the natural roll was in the critical range
+	Fumble            bool  //the natural roll was in the fumble range, or a roll-under challenge fumbled
+	Confirmation      []int //rolls of the confirmation roll, if one was needed
+	ConfirmationTotal int
+	Degree            Degree       //degree of success when the options include degree rules, shifted to agree with a natural roll
+	Level             SuccessLevel //level of success for roll-under challenges, shifted to agree with a natural roll
+	Breakdown         string       //a readable summary, e.g. "1d20+3 rolled [14] for 17 against 15: success by 2"
+}
+
 //RollChallenge rolls an expression against a provided value. The rolled value must be greater
 //than the challenge value to succeed. If desired the challenge can succeed on equal values
 //by setting equalSucceeds to true. You can also be alerted when specific values are rolled
 //by providing a slice of values, if any were rolled they will be returned.
 //
+//RollChallengeWithOptions returns more detail and supports more kinds of challenges.
+//
 //An error is returned if the expression is not a valid roll expression.
 func RollChallenge(expression string, against int, equalSucceeds bool, alertOn []int) (bool, int, []int, error) {
-	rolls, result, err := RollExpression(expression)
+	result, err := RollChallengeWithOptions(expression, against, ChallengeOptions{EqualSucceeds: equalSucceeds, AlertOn: alertOn})
 	if err != nil {
 		return false, 0, nil, err
 	}
 
-	succeeded := result > against
+	return result.Success, result.Total, result.Alerts, nil
+}
+
+//RollChallengeWithOptions rolls an expression against a target judged by the options. Roll-over challenges can be graded
+//by degree of success, roll-under challenges by level of success, and either can have natural rolls that always succeed
+//or fail. Natural rules are applied last, so a natural critical always succeeds and a natural fumble always fails, and
+//a degree or level that disagrees is shifted to the nearest one that agrees, e.g. a natural fumble grades a hard success
+//as a failure.
+//
+//An error is returned if the expression is not a valid roll expression.
+func RollChallengeWithOptions(expression string, target int, opts ChallengeOptions) (RollChallengeResult, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return RollChallengeResult{}, err
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return rollChallenge(expression, parsed, target, opts, New(seed)), nil
+}
+
+//rollChallenge rolls a challenge using the provided seeder, see RollChallengeWithOptions for details.
+func rollChallenge(expression string, parsed parsedExpression, target int, opts ChallengeOptions, seeder *seeder) RollChallengeResult {
+	result := RollChallengeResult{Target: target}
+	result.Rolls, result.Natural, result.Total = parsed.roll(seeder)
+	result.Alerts = alerts(result.Rolls, opts.AlertOn)
+
+	succeeds := func(total int) bool {
+		if opts.Mode == ChallengeUnder {
+			return opts.RollUnder.result(nil, total, target).Level.Succeeded()
+		}
+		if opts.Degrees != nil {
+			return total >= target
+		}
+		return beats(total, target, opts.EqualSucceeds)
+	}
+
+	switch {
+	case opts.Mode == ChallengeUnder:
+		result.Margin = target - result.Total
+		result.Level = opts.RollUnder.result(nil, result.Total, target).Level
+		result.Success = result.Level.Succeeded()
+		result.Fumble = result.Level == LevelFumble
+	case opts.Degrees != nil:
+		result.Margin = result.Total - target
+		result.Degree = opts.Degrees.degree(result.Margin, result.Natural)
+		result.Success = result.Degree.Succeeded()
+	default:
+		result.Margin = result.Total - target
+		result.Success = succeeds(result.Total)
+	}
+
+	success, critical, threat, fumble := opts.Natural.judge(result.Natural, result.Success, func() bool {
+		result.Confirmation, _, result.ConfirmationTotal = parsed.roll(seeder)
+		return succeeds(result.ConfirmationTotal)
+	})
+	result.Success, result.Critical, result.Threat = success, critical, threat
 
-	if !succeeded && equalSucceeds {
-		succeeded = result == against
+	//a natural roll that overrides the success shifts the degree or level to the nearest one that agrees with it
+	switch {
+	case opts.Mode == ChallengeUnder && success && !result.Level.Succeeded():
+		result.Level = LevelRegular
+	case opts.Mode == ChallengeUnder && !success && result.Level.Succeeded():
+		result.Level = LevelFailure
+	case opts.Mode == ChallengeOver && opts.Degrees != nil && success && !result.Degree.Succeeded():
+		result.Degree = Success
+	case opts.Mode == ChallengeOver && opts.Degrees != nil && !success && result.Degree.Succeeded():
+		result.Degree = Failure
 	}
+	result.Fumble = opts.Mode == ChallengeUnder && result.Level == LevelFumble || fumble
+	result.Breakdown = result.breakdown(expression, opts)
+
+	return result
+}
 
+//alerts returns every alertOn value that was rolled, once for each roll.
+func alerts(rolls []int, alertOn []int) []int {
 	var found []int
-	if len(alertOn) > 0 {
-		for _, roll := range rolls {
-			for _, check := range alertOn {
-				if roll == check {
-					found = append(found, check)
-					break
-				}
+	for _, roll := range rolls {
+		for _, check := range alertOn {
+			if roll == check {
+				found = append(found, check)
+				break
 			}
 		}
 	}
 
-	return succeeded, result, found, nil
+	return found
+}
+
+//breakdown describes the result, e.g. "1d20+3 rolled [14] for 17 against 15: success by 2".
+func (r RollChallengeResult) breakdown(expression string, opts ChallengeOptions) string {
+	outcome := "failure"
+	switch {
+	case r.Critical:
+		outcome = "critical success"
+	case r.Fumble:
+		outcome = "fumble"
+	case opts.Mode == ChallengeUnder:
+		outcome = r.Level.String()
+	case opts.Mode == ChallengeOver && opts.Degrees != nil:
+		outcome = r.Degree.String()
+	case r.Success:
+		outcome = "success"
+	}
+
+	against := "against"
+	if opts.Mode == ChallengeUnder {
+		against = "under"
+	}
+
+	breakdown := fmt.Sprintf("%s rolled %v for %d %s %d: %s", expression, r.Rolls, r.Total, against, r.Target, outcome)
+	//the margin is worded by outcome, a failure can still meet or beat the target, e.g. when it fumbles
+	switch {
+	case r.Margin < 0:
+		breakdown += fmt.Sprintf(", short by %d", -r.Margin)
+	case r.Success:
+		breakdown += fmt.Sprintf(" by %d", r.Margin)
+	case r.Margin == 0 && opts.Mode == ChallengeOver && opts.Degrees == nil && !opts.EqualSucceeds:
+		breakdown += ", met the target but needed to beat it"
+	case r.Margin == 0:
+		breakdown += " despite meeting the target"
+	default:
+		breakdown += fmt.Sprintf(" despite beating the target by %d", r.Margin)
+	}
+	if r.Threat && opts.Natural.Confirm {
+		confirmed := "not confirmed"
+		if r.Critical {
+			confirmed = "confirmed"
+		}
+		breakdown += fmt.Sprintf(", critical %s with %v for %d", confirmed, r.Confirmation, r.ConfirmationTotal)
+	}
+
+	return breakdown
+}
+
+//ChallengeOdds holds the exact chances of a challenge succeeding or failing.
+type ChallengeOdds struct {
+	Success *big.Rat
+	Failure *big.Rat
+	Alerts  map[int]*big.Rat //chance of each alertOn value being rolled at least once
+}
+
+//Percent returns the chance of success as a percentage.
+func (o ChallengeOdds) Percent() float64 {
+	f, _ := o.Success.Float64()
+	return f * 100
+}
+
+//ChallengeProbability calculates the exact chance of a RollChallenge with the same arguments succeeding
+//and failing. The chance of each alertOn value being rolled on at least one die is also returned.
+//
+//An error is returned if the expression is not a valid roll expression, or is too large to compute exactly.
+func ChallengeProbability(expression string, against int, equalSucceeds bool, alertOn []int) (ChallengeOdds, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return ChallengeOdds{}, err
+	}
+
+	d, err := parsed.exactDistribution()
+	if err != nil {
+		return ChallengeOdds{}, err
+	}
+
+	highestFailure := against
+	if equalSucceeds {
+		highestFailure = against - 1
+	}
+
+	odds := ChallengeOdds{Failure: d.ExactCumulative(highestFailure)}
+	odds.Success = new(big.Rat).Sub(big.NewRat(1, 1), odds.Failure)
+
+	if len(alertOn) > 0 {
+		odds.Alerts = make(map[int]*big.Rat)
+		for _, check := range alertOn {
+			odds.Alerts[check] = parsed.chanceRolled(check)
+		}
+	}
+
+	return odds, nil
+}
+
+//chanceRolled returns the chance of at least one die in the expression landing on value.
+func (p parsedExpression) chanceRolled(value int) *big.Rat {
+	never := big.NewRat(1, 1)
+	never.Mul(never, chanceNeverRolled(p.number, p.sides, value))
+	if p.hasSecondExpression {
+		never.Mul(never, chanceNeverRolled(p.secondNumber, p.secondSides, value))
+	}
+
+	return never.Sub(big.NewRat(1, 1), never)
+}
+
+//chanceNeverRolled returns the chance of none of the n-sided dice landing on value.
+func chanceNeverRolled(number int, sides int, value int) *big.Rat {
+	//zero sided dice always land on zero
+	if sides == 0 {
+		if value == 0 && number > 0 {
+			return new(big.Rat)
+		}
+		return big.NewRat(1, 1)
+	}
+
+	if value < 1 || value > sides {
+		return big.NewRat(1, 1)
+	}
+
+	n := big.NewInt(int64(number))
+	misses := new(big.Int).Exp(big.NewInt(int64(sides-1)), n, nil)
+	ways := new(big.Int).Exp(big.NewInt(int64(sides)), n, nil)
+
+	return new(big.Rat).SetFrac(misses, ways)
 }
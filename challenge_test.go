@@ -2,6 +2,8 @@ package dice
 
 import (
 	"fmt"
+	"math/big"
+	"reflect"
 	"testing"
 )
 
@@ -122,3 +124,196 @@ func Test_RollChallenge(t *testing.T) {
 		})
 	}
 }
+
+func Test_ChallengeProbability(t *testing.T) {
+	testCases := []struct {
+		expression    string
+		against       int
+		equalSucceeds bool
+		alert         []int
+		success       *big.Rat
+		alerts        map[int]*big.Rat
+		err           error
+	}{
+		{
+			expression: "1d20+3",
+			against:    13,
+			success:    big.NewRat(10, 20),
+		},
+		{
+			expression:    "1d20+3",
+			against:       13,
+			equalSucceeds: true,
+			success:       big.NewRat(11, 20),
+		},
+		{
+			expression: "2d6",
+			against:    7,
+			alert:      []int{6, 7},
+			success:    big.NewRat(15, 36),
+			alerts:     map[int]*big.Rat{6: big.NewRat(11, 36), 7: big.NewRat(0, 1)},
+		},
+		{
+			expression: "1d20+1d4",
+			against:    30,
+			alert:      []int{4},
+			success:    big.NewRat(0, 1),
+			alerts:     map[int]*big.Rat{4: big.NewRat(23, 80)},
+		},
+		{
+			expression:    "0d4+2",
+			against:       2,
+			equalSucceeds: true,
+			alert:         []int{2},
+			success:       big.NewRat(1, 1),
+			alerts:        map[int]*big.Rat{2: big.NewRat(0, 1)},
+		},
+		{
+			expression: "hey0+3",
+			against:    5,
+			err:        ErrInvalidRollExpression,
+		},
+		{
+			expression: "dropL:dropH:100d100",
+			against:    5,
+			err:        ErrDistributionTooLarge,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s", i, tc.expression), func(t *testing.T) {
+			got, err := ChallengeProbability(tc.expression, tc.against, tc.equalSucceeds, tc.alert)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Success.Cmp(tc.success) != 0 {
+				t.Errorf("[success] want %s, got %s", tc.success.RatString(), got.Success.RatString())
+			}
+
+			failure := new(big.Rat).Sub(big.NewRat(1, 1), tc.success)
+			if got.Failure.Cmp(failure) != 0 {
+				t.Errorf("[failure] want %s, got %s", failure.RatString(), got.Failure.RatString())
+			}
+
+			if len(got.Alerts) != len(tc.alerts) {
+				t.Errorf("[alerts] want %d, got %d", len(tc.alerts), len(got.Alerts))
+			}
+			for value, want := range tc.alerts {
+				if got.Alerts[value] == nil || got.Alerts[value].Cmp(want) != 0 {
+					t.Errorf("[alert %d] want %s, got %v", value, want.RatString(), got.Alerts[value])
+				}
+			}
+		})
+	}
+}
+
+func TestChallengeOdds_Percent(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		subject := ChallengeOdds{Success: big.NewRat(13, 20)}
+		if got := subject.Percent(); got != 65 {
+			t.Errorf("want 65, got %f", got)
+		}
+	})
+}
+
+func TestRollChallengeWithOptions(t *testing.T) {
+	pathfinder := DegreeRules{}
+	tests := map[string]struct {
+		expression string
+		target     int
+		opts       ChallengeOptions
+		want       RollChallengeResult
+		wantErr    error
+	}{
+		"roll over": {
+			expression: "2d1+3", target: 4, opts: ChallengeOptions{AlertOn: []int{1}},
+			want: RollChallengeResult{Success: true, Rolls: []int{1, 1}, Natural: 2, Total: 5, Target: 4, Margin: 1, Alerts: []int{1, 1},
+				Breakdown: "2d1+3 rolled [1 1] for 5 against 4: success by 1"},
+		},
+		"roll over fails on equal": {
+			expression: "1d1+3", target: 4,
+			want: RollChallengeResult{Rolls: []int{1}, Natural: 1, Total: 4, Target: 4, Breakdown: "1d1+3 rolled [1] for 4 against 4: failure, met the target but needed to beat it"},
+		},
+		"roll over succeeds on equal": {
+			expression: "1d1+3", target: 4, opts: ChallengeOptions{EqualSucceeds: true},
+			want: RollChallengeResult{Success: true, Rolls: []int{1}, Natural: 1, Total: 4, Target: 4, Breakdown: "1d1+3 rolled [1] for 4 against 4: success by 0"},
+		},
+		"fumble on equal": {
+			expression: "1d1+3", target: 4, opts: ChallengeOptions{EqualSucceeds: true, Natural: NaturalRules{FumbleRange: 1}},
+			want: RollChallengeResult{Rolls: []int{1}, Natural: 1, Total: 4, Target: 4, Fumble: true, Breakdown: "1d1+3 rolled [1] for 4 against 4: fumble despite meeting the target"},
+		},
+		"degrees": {
+			expression: "1d1+13", target: 4, opts: ChallengeOptions{Degrees: &pathfinder},
+			want: RollChallengeResult{Success: true, Rolls: []int{1}, Natural: 1, Total: 14, Target: 4, Margin: 10, Degree: CriticalSuccess,
+				Breakdown: "1d1+13 rolled [1] for 14 against 4: critical success by 10"},
+		},
+		"roll under": {
+			expression: "1d1+9", target: 50, opts: ChallengeOptions{Mode: ChallengeUnder, RollUnder: CallOfCthulhuRules()},
+			want: RollChallengeResult{Success: true, Rolls: []int{1}, Natural: 1, Total: 10, Target: 50, Margin: 40, Level: LevelExtreme,
+				Breakdown: "1d1+9 rolled [1] for 10 under 50: extreme success by 40"},
+		},
+		"roll under fumble": {
+			expression: "1d1+99", target: 50, opts: ChallengeOptions{Mode: ChallengeUnder, RollUnder: CallOfCthulhuRules()},
+			want: RollChallengeResult{Rolls: []int{1}, Natural: 1, Total: 100, Target: 50, Margin: -50, Level: LevelFumble, Fumble: true,
+				Breakdown: "1d1+99 rolled [1] for 100 under 50: fumble, short by 50"},
+		},
+		"natural fumble overrides total": {
+			expression: "1d1+20", target: 4, opts: ChallengeOptions{Natural: NaturalRules{FumbleRange: 1}},
+			want: RollChallengeResult{Rolls: []int{1}, Natural: 1, Total: 21, Target: 4, Margin: 17, Fumble: true,
+				Breakdown: "1d1+20 rolled [1] for 21 against 4: fumble despite beating the target by 17"},
+		},
+		"unconfirmed critical": {
+			expression: "1d1", target: 4, opts: ChallengeOptions{Natural: NaturalRules{CritRange: 1, Confirm: true}},
+			want: RollChallengeResult{Success: true, Rolls: []int{1}, Natural: 1, Total: 1, Target: 4, Margin: -3, Threat: true, Confirmation: []int{1}, ConfirmationTotal: 1,
+				Breakdown: "1d1 rolled [1] for 1 against 4: success, short by 3, critical not confirmed with [1] for 1"},
+		},
+		"natural critical shifts the degree": {
+			expression: "1d1", target: 15, opts: ChallengeOptions{Degrees: &pathfinder, Natural: NaturalRules{CritRange: 1}},
+			want: RollChallengeResult{Success: true, Rolls: []int{1}, Natural: 1, Total: 1, Target: 15, Margin: -14, Critical: true, Threat: true, Degree: Success,
+				Breakdown: "1d1 rolled [1] for 1 against 15: critical success, short by 14"},
+		},
+		"natural fumble shifts the degree": {
+			expression: "1d1+30", target: 15, opts: ChallengeOptions{Degrees: &pathfinder, Natural: NaturalRules{FumbleRange: 1}},
+			want: RollChallengeResult{Rolls: []int{1}, Natural: 1, Total: 31, Target: 15, Margin: 16, Fumble: true, Degree: Failure,
+				Breakdown: "1d1+30 rolled [1] for 31 against 15: fumble despite beating the target by 16"},
+		},
+		"natural fumble shifts the level": {
+			expression: "1d1+9", target: 50, opts: ChallengeOptions{Mode: ChallengeUnder, RollUnder: CallOfCthulhuRules(), Natural: NaturalRules{FumbleRange: 1}},
+			want: RollChallengeResult{Rolls: []int{1}, Natural: 1, Total: 10, Target: 50, Margin: 40, Fumble: true, Level: LevelFailure,
+				Breakdown: "1d1+9 rolled [1] for 10 under 50: fumble despite beating the target by 40"},
+		},
+		"natural critical shifts the level": {
+			expression: "1d1+99", target: 50, opts: ChallengeOptions{Mode: ChallengeUnder, RollUnder: CallOfCthulhuRules(), Natural: NaturalRules{CritRange: 1}},
+			want: RollChallengeResult{Success: true, Rolls: []int{1}, Natural: 1, Total: 100, Target: 50, Margin: -50, Critical: true, Threat: true, Level: LevelRegular,
+				Breakdown: "1d1+99 rolled [1] for 100 under 50: critical success, short by 50"},
+		},
+		"error on invalid expression": {expression: "1d", target: 4, wantErr: ErrInvalidRollExpression},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := RollChallengeWithOptions(tc.expression, tc.target, tc.opts)
+			if err != tc.wantErr {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRollChallengeWithOptions_seed(t *testing.T) {
+	opts := ChallengeOptions{Seed: 42, Natural: NaturalRules{CritRange: 19, Confirm: true}}
+	first, _ := RollChallengeWithOptions("3d20+2", 20, opts)
+	for i := 0; i < 5; i++ {
+		if got, _ := RollChallengeWithOptions("3d20+2", 20, opts); !reflect.DeepEqual(got, first) {
+			t.Errorf("want %+v, got %+v", first, got)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package dice
+
+import "math/big"
+
+//Comparison holds the results of comparing two roll expressions, A and B, head-to-head.
+type Comparison struct {
+	Greater        *big.Rat      //chance A rolls higher than B
+	Equal          *big.Rat      //chance A and B roll the same
+	Less           *big.Rat      //chance A rolls lower than B
+	MeanDifference *big.Rat      //expected value of A minus the expected value of B
+	Difference     *Distribution //distribution of A minus B
+	Exact          bool          //false when either expression had to be estimated by simulation
+}
+
+//Compare rolls expression a against expression b and reports how often each wins.
+//The comparison is exact when possible, expressions that are too large to compute exactly
+//are estimated by simulating many rolls instead.
+//
+//An error is returned if either expression is not a valid roll expression.
+func Compare(a string, b string) (Comparison, error) {
+	parsedA, err := parseExpression(a)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	parsedB, err := parseExpression(b)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	distA := parsedA.distribution()
+	distB := parsedB.distribution()
+	difference := distA.convolve(distB.transform(func(v int) int { return -v }))
+
+	lessOrEqual := difference.ExactCumulative(0)
+
+	return Comparison{
+		Greater:        new(big.Rat).Sub(big.NewRat(1, 1), lessOrEqual),
+		Equal:          difference.ExactProbability(0),
+		Less:           difference.ExactCumulative(-1),
+		MeanDifference: new(big.Rat).Sub(distA.ExactMean(), distB.ExactMean()),
+		Difference:     difference,
+		Exact:          difference.Exact(),
+	}, nil
+}
@@ -0,0 +1,99 @@
+package dice
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func Test_Compare(t *testing.T) {
+	testCases := []struct {
+		a              string
+		b              string
+		greater        *big.Rat
+		equal          *big.Rat
+		less           *big.Rat
+		meanDifference *big.Rat
+		err            error
+	}{
+		{
+			a:              "2d6",
+			b:              "1d12",
+			greater:        big.NewRat(1, 2),
+			equal:          big.NewRat(1, 12),
+			less:           big.NewRat(5, 12),
+			meanDifference: big.NewRat(1, 2),
+		},
+		{
+			a:              "1d6",
+			b:              "1d6",
+			greater:        big.NewRat(15, 36),
+			equal:          big.NewRat(6, 36),
+			less:           big.NewRat(15, 36),
+			meanDifference: big.NewRat(0, 1),
+		},
+		{
+			a:              "1d4+10",
+			b:              "max:2d8",
+			greater:        big.NewRat(1, 1),
+			equal:          big.NewRat(0, 1),
+			less:           big.NewRat(0, 1),
+			meanDifference: new(big.Rat).Sub(big.NewRat(25, 2), big.NewRat(372, 64)),
+		},
+		{
+			a:   "heyo",
+			b:   "1d6",
+			err: ErrInvalidRollExpression,
+		},
+		{
+			a:   "1d6",
+			b:   "2d-6",
+			err: ErrInvalidRollExpression,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s vs %s", i, tc.a, tc.b), func(t *testing.T) {
+			got, err := Compare(tc.a, tc.b)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if !got.Exact {
+				t.Error("[exact] want true, got false")
+			}
+			if got.Greater.Cmp(tc.greater) != 0 {
+				t.Errorf("[greater] want %s, got %s", tc.greater.RatString(), got.Greater.RatString())
+			}
+			if got.Equal.Cmp(tc.equal) != 0 {
+				t.Errorf("[equal] want %s, got %s", tc.equal.RatString(), got.Equal.RatString())
+			}
+			if got.Less.Cmp(tc.less) != 0 {
+				t.Errorf("[less] want %s, got %s", tc.less.RatString(), got.Less.RatString())
+			}
+			if got.MeanDifference.Cmp(tc.meanDifference) != 0 {
+				t.Errorf("[mean difference] want %s, got %s", tc.meanDifference.RatString(), got.MeanDifference.RatString())
+			}
+			if got.Difference.ExactMean().Cmp(tc.meanDifference) != 0 {
+				t.Errorf("[difference mean] want %s, got %s", tc.meanDifference.RatString(), got.Difference.ExactMean().RatString())
+			}
+		})
+	}
+
+	t.Run("estimates large expressions", func(t *testing.T) {
+		got, err := Compare("dropL:dropH:100d100", "1d6")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if got.Exact {
+			t.Error("[exact] want false, got true")
+		}
+		if got.Greater.Cmp(big.NewRat(1, 1)) != 0 {
+			t.Errorf("[greater] want 1, got %s", got.Greater.RatString())
+		}
+	})
+}
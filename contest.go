@@ -0,0 +1,91 @@
+package dice
+
+import "time"
+
+//maxContestRerolls is the number of times a tied contest is rerolled before giving up.
+const maxContestRerolls = 100
+
+//TieRule decides the outcome of a contest when both sides roll the same total.
+type TieRule int
+
+const (
+	//TieDefenderWins gives a tied contest to the defender.
+	TieDefenderWins TieRule = iota
+	//TieReroll rolls both sides again until the tie is broken.
+	TieReroll
+	//TieBothFail ends a tied contest with no winner.
+	TieBothFail
+)
+
+//ContestWinner identifies which side won a contest.
+type ContestWinner int
+
+const (
+	//NoWinner is the result of a tie under TieBothFail.
+	NoWinner ContestWinner = iota
+	//AttackerWins is the result when the attacker rolls higher.
+	AttackerWins
+	//DefenderWins is the result when the defender rolls higher, or ties under TieDefenderWins.
+	DefenderWins
+)
+
+//ContestRoll is one side's roll in a contest.
+type ContestRoll struct {
+	Rolls []int
+	Total int
+}
+
+//ContestResult holds both sides' rolls in a contest and who won.
+type ContestResult struct {
+	Attacker ContestRoll
+	Defender ContestRoll
+	Winner   ContestWinner
+	Margin   int //how much the winner rolled over the loser, 0 on a tie
+	Rerolls  int //number of times a tie was rerolled under TieReroll
+}
+
+//RollContest rolls the attacker's expression against the defender's, the higher total wins.
+//Ties are settled by the tie rule, under TieReroll both sides are rolled again and only the final rolls are returned.
+//
+//An error is returned if either expression is not a valid roll expression, or a contest under TieReroll is
+//still tied after many rerolls (e.g. 1d1 against 1d1).
+func RollContest(attacker string, defender string, tie TieRule) (ContestResult, error) {
+	parsedAttacker, err := parseExpression(attacker)
+	if err != nil {
+		return ContestResult{}, err
+	}
+	parsedDefender, err := parseExpression(defender)
+	if err != nil {
+		return ContestResult{}, err
+	}
+
+	return rollContest(parsedAttacker, parsedDefender, tie, New(time.Now().UnixNano()))
+}
+
+//rollContest rolls a contest using the provided seeder, see RollContest for details.
+func rollContest(attacker parsedExpression, defender parsedExpression, tie TieRule, seeder *seeder) (ContestResult, error) {
+	var result ContestResult
+	for {
+		result.Attacker.Rolls, _, result.Attacker.Total = attacker.roll(seeder)
+		result.Defender.Rolls, _, result.Defender.Total = defender.roll(seeder)
+		if result.Attacker.Total != result.Defender.Total || tie != TieReroll {
+			break
+		}
+
+		if result.Rerolls == maxContestRerolls {
+			return result, ErrContestTied
+		}
+		result.Rerolls++
+	}
+
+	switch margin := result.Attacker.Total - result.Defender.Total; {
+	case margin > 0:
+		result.Winner, result.Margin = AttackerWins, margin
+	case margin < 0:
+		result.Winner, result.Margin = DefenderWins, -margin
+	case tie == TieDefenderWins:
+		result.Winner = DefenderWins
+	}
+
+	return result, nil
+}
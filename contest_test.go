@@ -0,0 +1,112 @@
+package dice
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRollContest(t *testing.T) {
+	testCases := []struct {
+		attacker string
+		defender string
+		tie      TieRule
+		winner   ContestWinner
+		margin   int
+		err      error
+	}{
+		{
+			attacker: "1d1+5",
+			defender: "2d1",
+			winner:   AttackerWins,
+			margin:   4,
+		},
+		{
+			attacker: "1d1",
+			defender: "3d1+1",
+			tie:      TieBothFail,
+			winner:   DefenderWins,
+			margin:   3,
+		},
+		{
+			attacker: "1d1+2",
+			defender: "3d1",
+			tie:      TieDefenderWins,
+			winner:   DefenderWins,
+		},
+		{
+			attacker: "1d1+2",
+			defender: "3d1",
+			tie:      TieBothFail,
+			winner:   NoWinner,
+		},
+		{
+			attacker: "1d1",
+			defender: "1d1",
+			tie:      TieReroll,
+			err:      ErrContestTied,
+		},
+		{
+			attacker: "1d",
+			defender: "1d6",
+			err:      ErrInvalidRollExpression,
+		},
+		{
+			attacker: "1d6",
+			defender: "d",
+			err:      ErrInvalidRollExpression,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s vs %s", i, tc.attacker, tc.defender), func(t *testing.T) {
+			got, err := RollContest(tc.attacker, tc.defender, tc.tie)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Winner != tc.winner {
+				t.Errorf("[winner] want %d, got %d", tc.winner, got.Winner)
+			}
+			if got.Margin != tc.margin {
+				t.Errorf("[margin] want %d, got %d", tc.margin, got.Margin)
+			}
+		})
+	}
+}
+
+func Test_rollContest(t *testing.T) {
+	t.Run("ties are rerolled", func(t *testing.T) {
+		attacker, _ := parseExpression("1d2")
+		defender, _ := parseExpression("1d2")
+
+		rerolled := false
+		for seed := int64(1); seed <= 20; seed++ {
+			got, err := rollContest(attacker, defender, TieReroll, New(seed))
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			if got.Attacker.Total == got.Defender.Total || got.Winner == NoWinner || got.Margin != 1 {
+				t.Errorf("[seed %d] tie was not broken, got %v", seed, got)
+			}
+			rerolled = rerolled || got.Rerolls > 0
+		}
+		if !rerolled {
+			t.Errorf("want at least one reroll in 20 contests")
+		}
+	})
+
+	t.Run("rolls are returned", func(t *testing.T) {
+		attacker, _ := parseExpression("2d1+1")
+		defender, _ := parseExpression("1d1")
+
+		got, _ := rollContest(attacker, defender, TieDefenderWins, New(1))
+		want := ContestResult{Attacker: ContestRoll{Rolls: []int{1, 1}, Total: 3}, Defender: ContestRoll{Rolls: []int{1}, Total: 1}, Winner: AttackerWins, Margin: 2}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package dice
+
+import "time"
+
+//defaultCriticalMargin is how far a challenge must beat or miss its DC by to be critical unless changed.
+const defaultCriticalMargin = 10
+
+//Degree is how well a challenge succeeded or failed.
+type Degree int
+
+const (
+	//CriticalFailure is a failure by the critical margin or more.
+	CriticalFailure Degree = iota
+	//Failure is a roll below the DC.
+	Failure
+	//Success is a roll that meets or beats the DC.
+	Success
+	//CriticalSuccess is a success by the critical margin or more.
+	CriticalSuccess
+)
+
+func (d Degree) String() string {
+	switch d {
+	case CriticalFailure:
+		return "critical failure"
+	case Failure:
+		return "failure"
+	case Success:
+		return "success"
+	case CriticalSuccess:
+		return "critical success"
+	}
+
+	return "unknown"
+}
+
+//Succeeded returns true for a success or critical success.
+func (d Degree) Succeeded() bool {
+	return d >= Success
+}
+
+//DegreeRules controls how the margin and natural roll of a challenge decide its degree of success.
+type DegreeRules struct {
+	CriticalSuccessMargin int //beating the DC by this much or more is a critical success, defaults to 10, negative disables
+	CriticalFailureMargin int //missing the DC by this much or more is a critical failure, defaults to 10, negative disables
+	NaturalHigh           int //natural rolls at or above this improve the degree one step, 0 disables
+	NaturalLow            int //natural rolls at or below this worsen the degree one step, 0 disables
+}
+
+//PathfinderDegrees returns the Pathfinder 2e rules, critical by 10 either way with a natural 20 or 1 shifting the degree one step.
+func PathfinderDegrees() DegreeRules {
+	return DegreeRules{CriticalSuccessMargin: 10, CriticalFailureMargin: 10, NaturalHigh: 20, NaturalLow: 1}
+}
+
+//DegreeResult holds a challenge roll and its degree of success.
+type DegreeResult struct {
+	Degree  Degree
+	Rolls   []int
+	Natural int //the first dice before modifiers, e.g. the d20 of 1d20+7
+	Total   int
+	Margin  int //total minus the DC, negative when the roll fell short
+}
+
+//RollDegrees rolls an expression against a DC and returns its degree of success. Meeting or beating the DC succeeds,
+//beating it by the critical success margin is a critical success, and missing it by the critical failure margin is a
+//critical failure. A natural roll in the high or low range then shifts the result one step up or down.
+//
+//An error is returned if the expression is not a valid roll expression.
+func RollDegrees(expression string, dc int, rules DegreeRules) (DegreeResult, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return DegreeResult{}, err
+	}
+
+	result := DegreeResult{}
+	result.Rolls, result.Natural, result.Total = parsed.roll(New(time.Now().UnixNano()))
+	result.Margin = result.Total - dc
+	result.Degree = rules.degree(result.Margin, result.Natural)
+
+	return result, nil
+}
+
+//degree returns the degree of success for a roll that beat the DC by margin with the natural roll.
+func (r DegreeRules) degree(margin int, natural int) Degree {
+	successMargin, failureMargin := r.CriticalSuccessMargin, r.CriticalFailureMargin
+	if successMargin == 0 {
+		successMargin = defaultCriticalMargin
+	}
+	if failureMargin == 0 {
+		failureMargin = defaultCriticalMargin
+	}
+
+	degree := Failure
+	switch {
+	case successMargin > 0 && margin >= successMargin:
+		degree = CriticalSuccess
+	case margin >= 0:
+		degree = Success
+	case failureMargin > 0 && margin <= -failureMargin:
+		degree = CriticalFailure
+	}
+
+	switch {
+	case r.NaturalHigh > 0 && natural >= r.NaturalHigh && degree < CriticalSuccess:
+		degree++
+	case r.NaturalLow > 0 && natural <= r.NaturalLow && degree > CriticalFailure:
+		degree--
+	}
+
+	return degree
+}
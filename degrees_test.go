@@ -0,0 +1,121 @@
+package dice
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRollDegrees(t *testing.T) {
+	testCases := []struct {
+		expression string
+		dc         int
+		rules      DegreeRules
+		degree     Degree
+		margin     int
+		err        error
+	}{
+		{
+			expression: "1d1+14",
+			dc:         15,
+			rules:      DegreeRules{},
+			degree:     Success,
+		},
+		{
+			expression: "1d1+13",
+			dc:         15,
+			rules:      DegreeRules{},
+			degree:     Failure,
+			margin:     -1,
+		},
+		{
+			expression: "1d1+24",
+			dc:         15,
+			rules:      DegreeRules{},
+			degree:     CriticalSuccess,
+			margin:     10,
+		},
+		{
+			expression: "1d1+4",
+			dc:         15,
+			rules:      DegreeRules{},
+			degree:     CriticalFailure,
+			margin:     -10,
+		},
+		{
+			expression: "1d1+13",
+			dc:         15,
+			rules:      DegreeRules{NaturalHigh: 1},
+			degree:     Success,
+			margin:     -1,
+		},
+		{
+			expression: "1d1+14",
+			dc:         15,
+			rules:      PathfinderDegrees(),
+			degree:     Failure,
+		},
+		{
+			expression: "1d1",
+			dc:         15,
+			rules:      PathfinderDegrees(),
+			degree:     CriticalFailure,
+			margin:     -14,
+		},
+		{
+			expression: "1d1+30",
+			dc:         15,
+			rules:      DegreeRules{NaturalHigh: 1},
+			degree:     CriticalSuccess,
+			margin:     16,
+		},
+		{
+			expression: "1d1+19",
+			dc:         15,
+			rules:      DegreeRules{CriticalSuccessMargin: 5, CriticalFailureMargin: 5},
+			degree:     CriticalSuccess,
+			margin:     5,
+		},
+		{
+			expression: "1d1",
+			dc:         50,
+			rules:      DegreeRules{CriticalFailureMargin: -1},
+			degree:     Failure,
+			margin:     -49,
+		},
+		{
+			expression: "1d",
+			dc:         15,
+			err:        ErrInvalidRollExpression,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s vs %d", i, tc.expression, tc.dc), func(t *testing.T) {
+			got, err := RollDegrees(tc.expression, tc.dc, tc.rules)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Degree != tc.degree {
+				t.Errorf("[degree] want %s, got %s", tc.degree, got.Degree)
+			}
+			if got.Margin != tc.margin {
+				t.Errorf("[margin] want %d, got %d", tc.margin, got.Margin)
+			}
+			if got.Natural != 1 || len(got.Rolls) != 1 {
+				t.Errorf("[natural] want 1 from one roll, got %d from %v", got.Natural, got.Rolls)
+			}
+		})
+	}
+}
+
+func TestDegree_Succeeded(t *testing.T) {
+	for degree, want := range map[Degree]bool{CriticalFailure: false, Failure: false, Success: true, CriticalSuccess: true} {
+		if got := degree.Succeeded(); got != want {
+			t.Errorf("[%s] want %t, got %t", degree, want, got)
+		}
+	}
+}
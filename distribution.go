@@ -0,0 +1,500 @@
+package dice
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+const (
+	//maxExactOperations caps the work spent building an exact distribution before simulation is used instead.
+	maxExactOperations = 2e7
+	//defaultSimulationTrials is the most rolls used when a distribution has to be estimated.
+	defaultSimulationTrials = 100000
+	//minSimulationTrials is the fewest rolls used when a distribution has to be estimated, however many dice are rolled.
+	minSimulationTrials = 100
+	//maxSimulatedDice caps the dice rolled when a distribution has to be estimated, larger expressions are rolled fewer times.
+	maxSimulatedDice = 2e7
+)
+
+//Distribution holds every possible result of a roll expression along with how often it occurs.
+//Exact distributions count every outcome of the dice, while estimated distributions count the
+//results of simulated rolls.
+type Distribution struct {
+	low    int
+	counts []*big.Int
+	total  *big.Int
+	exact  bool
+}
+
+//ExactDistribution computes the exact distribution of the provided roll expression.
+//
+//An error is returned if the expression is not a valid roll expression, or if the expression
+//is too large to compute exactly.
+func ExactDistribution(expression string) (*Distribution, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed.exactDistribution()
+}
+
+//Exact returns true if the distribution was computed exactly and false if it was estimated by simulation.
+func (d *Distribution) Exact() bool {
+	return d.exact
+}
+
+//Min returns the lowest possible result.
+func (d *Distribution) Min() int {
+	outcomes := d.Outcomes()
+	if len(outcomes) == 0 {
+		return 0
+	}
+
+	return outcomes[0]
+}
+
+//Max returns the highest possible result.
+func (d *Distribution) Max() int {
+	outcomes := d.Outcomes()
+	if len(outcomes) == 0 {
+		return 0
+	}
+
+	return outcomes[len(outcomes)-1]
+}
+
+//Outcomes returns every result with a chance of occurring, lowest first.
+func (d *Distribution) Outcomes() []int {
+	var outcomes []int
+	for i, count := range d.counts {
+		if count.Sign() != 0 {
+			outcomes = append(outcomes, d.low+i)
+		}
+	}
+
+	return outcomes
+}
+
+//ExactProbability returns the exact probability of rolling the provided value.
+func (d *Distribution) ExactProbability(value int) *big.Rat {
+	return new(big.Rat).SetFrac(d.count(value), d.total)
+}
+
+//Probability returns the probability of rolling the provided value.
+func (d *Distribution) Probability(value int) float64 {
+	f, _ := d.ExactProbability(value).Float64()
+	return f
+}
+
+//ExactCumulative returns the exact probability of rolling the provided value or lower.
+func (d *Distribution) ExactCumulative(value int) *big.Rat {
+	sum := new(big.Int)
+	for i, count := range d.counts {
+		if d.low+i > value {
+			break
+		}
+		sum.Add(sum, count)
+	}
+
+	return new(big.Rat).SetFrac(sum, d.total)
+}
+
+//Cumulative returns the probability of rolling the provided value or lower.
+func (d *Distribution) Cumulative(value int) float64 {
+	f, _ := d.ExactCumulative(value).Float64()
+	return f
+}
+
+//ExactMean returns the exact expected value.
+func (d *Distribution) ExactMean() *big.Rat {
+	sum := new(big.Int)
+	term := new(big.Int)
+	for i, count := range d.counts {
+		term.Mul(big.NewInt(int64(d.low+i)), count)
+		sum.Add(sum, term)
+	}
+
+	return new(big.Rat).SetFrac(sum, d.total)
+}
+
+//Mean returns the expected value.
+func (d *Distribution) Mean() float64 {
+	f, _ := d.ExactMean().Float64()
+	return f
+}
+
+//ExactVariance returns the exact variance.
+func (d *Distribution) ExactVariance() *big.Rat {
+	squares := new(big.Int)
+	term := new(big.Int)
+	for i, count := range d.counts {
+		value := big.NewInt(int64(d.low + i))
+		term.Mul(value, value)
+		term.Mul(term, count)
+		squares.Add(squares, term)
+	}
+
+	mean := d.ExactMean()
+	variance := new(big.Rat).SetFrac(squares, d.total)
+
+	return variance.Sub(variance, mean.Mul(mean, mean))
+}
+
+//Variance returns the variance.
+func (d *Distribution) Variance() float64 {
+	f, _ := d.ExactVariance().Float64()
+	return f
+}
+
+//StdDev returns the standard deviation.
+func (d *Distribution) StdDev() float64 {
+	return math.Sqrt(d.Variance())
+}
+
+//Percentile returns the lowest result that is rolled at or above the provided percent (0-100) of the time.
+//
+//An error is returned if percent is not between 0 and 100.
+func (d *Distribution) Percentile(percent float64) (int, error) {
+	if math.IsNaN(percent) || percent < 0 || percent > 100 {
+		return 0, ErrInvalidPercentile
+	}
+
+	target, _ := new(big.Rat).SetString(strconv.FormatFloat(percent, 'g', -1, 64))
+	target.Quo(target, big.NewRat(100, 1))
+	sum := new(big.Int)
+	for i, count := range d.counts {
+		if count.Sign() == 0 {
+			continue
+		}
+		sum.Add(sum, count)
+		if new(big.Rat).SetFrac(sum, d.total).Cmp(target) >= 0 {
+			return d.low + i, nil
+		}
+	}
+
+	return d.Max(), nil
+}
+
+func (d *Distribution) count(value int) *big.Int {
+	i := value - d.low
+	if i < 0 || i >= len(d.counts) {
+		return new(big.Int)
+	}
+
+	return d.counts[i]
+}
+
+//newDistribution creates an empty exact distribution covering low to high.
+func newDistribution(low, high int) *Distribution {
+	d := &Distribution{low: low, total: new(big.Int), exact: true}
+	if high < low {
+		return d
+	}
+	d.counts = make([]*big.Int, high-low+1)
+	for i := range d.counts {
+		d.counts[i] = new(big.Int)
+	}
+
+	return d
+}
+
+//pointDistribution creates a distribution that always results in value.
+func pointDistribution(value int) *Distribution {
+	d := newDistribution(value, value)
+	d.counts[0].SetInt64(1)
+	d.total.SetInt64(1)
+
+	return d
+}
+
+//shift adds amount to every outcome.
+func (d *Distribution) shift(amount int) *Distribution {
+	d.low += amount
+	return d
+}
+
+//convolve returns the distribution of the sum of two independent distributions.
+func (d *Distribution) convolve(other *Distribution) *Distribution {
+	result := newDistribution(d.low+other.low, d.low+other.low+len(d.counts)+len(other.counts)-2)
+	term := new(big.Int)
+	for i, a := range d.counts {
+		if a.Sign() == 0 {
+			continue
+		}
+		for j, b := range other.counts {
+			if b.Sign() == 0 {
+				continue
+			}
+			term.Mul(a, b)
+			result.counts[i+j].Add(result.counts[i+j], term)
+		}
+	}
+	result.total.Mul(d.total, other.total)
+	result.exact = d.exact && other.exact
+
+	return result
+}
+
+//transform returns the distribution after applying fn to every outcome.
+func (d *Distribution) transform(fn func(int) int) *Distribution {
+	outcomes := d.Outcomes()
+	if len(outcomes) == 0 {
+		return d
+	}
+
+	low, high := fn(outcomes[0]), fn(outcomes[0])
+	for _, outcome := range outcomes {
+		low = min(low, fn(outcome))
+		high = max(high, fn(outcome))
+	}
+
+	result := newDistribution(low, high)
+	for _, outcome := range outcomes {
+		i := fn(outcome) - low
+		result.counts[i].Add(result.counts[i], d.count(outcome))
+	}
+	result.total.Set(d.total)
+	result.exact = d.exact
+
+	return result
+}
+
+//sumCounts returns the distribution of the sum of number dice that each land on a value from lo to hi.
+//Every die is counted as a single outcome so totals are comparable when lo and hi change.
+func sumCounts(number, lo, hi int) *Distribution {
+	if number == 0 {
+		return pointDistribution(0)
+	}
+	if hi < lo {
+		return newDistribution(0, -1)
+	}
+
+	width := hi - lo
+	current := []*big.Int{big.NewInt(1)}
+	for n := 0; n < number; n++ {
+		next := make([]*big.Int, len(current)+width)
+		window := new(big.Int)
+		for i := range next {
+			if i < len(current) {
+				window.Add(window, current[i])
+			}
+			if i-width-1 >= 0 {
+				window.Sub(window, current[i-width-1])
+			}
+			next[i] = new(big.Int).Set(window)
+		}
+		current = next
+	}
+
+	d := &Distribution{low: number * lo, counts: current, total: new(big.Int), exact: true}
+	for _, count := range current {
+		d.total.Add(d.total, count)
+	}
+
+	return d
+}
+
+//subtractCounts removes the counts of other from d, both distributions must count the same kind of outcome.
+func (d *Distribution) subtractCounts(other *Distribution) *Distribution {
+	for _, outcome := range other.Outcomes() {
+		i := outcome - d.low
+		d.counts[i].Sub(d.counts[i], other.count(outcome))
+	}
+	d.total.Sub(d.total, other.total)
+
+	return d
+}
+
+//addCounts merges the counts of other into d, growing d if needed.
+func (d *Distribution) addCounts(other *Distribution) *Distribution {
+	outcomes := other.Outcomes()
+	if len(outcomes) == 0 {
+		return d
+	}
+
+	if len(d.counts) == 0 {
+		grown := newDistribution(outcomes[0], outcomes[len(outcomes)-1])
+		grown.total.Set(d.total)
+		*d = *grown
+	}
+
+	low := min(d.low, outcomes[0])
+	high := max(d.low+len(d.counts)-1, outcomes[len(outcomes)-1])
+	if low != d.low || high != d.low+len(d.counts)-1 {
+		grown := newDistribution(low, high)
+		for i, count := range d.counts {
+			grown.counts[d.low+i-low].Set(count)
+		}
+		grown.total.Set(d.total)
+		grown.exact = d.exact
+		*d = *grown
+	}
+
+	for _, outcome := range outcomes {
+		i := outcome - d.low
+		d.counts[i].Add(d.counts[i], other.count(outcome))
+	}
+	d.total.Add(d.total, other.total)
+
+	return d
+}
+
+//naturalOperations estimates the work needed to compute the first expression's natural result exactly.
+func (p parsedExpression) naturalOperations() float64 {
+	n, s := float64(p.number), float64(p.sides)
+	switch {
+	case p.wantsMax || p.wantsMin:
+		return n * s
+	case p.dropLowest && p.dropHighest:
+		return s * s * n * n * s / 2
+	case p.dropLowest || p.dropHighest:
+		return s * n * n * s
+	}
+
+	return n * n * s
+}
+
+//naturalDistribution computes the distribution of the first expression's dice before modifiers,
+//following the same max, min, and drop rules used when rolling.
+func (p parsedExpression) naturalDistribution() *Distribution {
+	//zero dice or zero sided dice always result in zero
+	if p.number == 0 || p.sides == 0 {
+		return pointDistribution(0)
+	}
+
+	if p.wantsMax || p.wantsMin {
+		d := newDistribution(1, p.sides)
+		number := big.NewInt(int64(p.number))
+		for value := 1; value <= p.sides; value++ {
+			//ways for every die to be at or beyond value, minus the ways for every die to be strictly beyond it,
+			//max wins when both are asked for, the same as when rolling
+			at, beyond := int64(value), int64(value-1)
+			if !p.wantsMax {
+				at, beyond = int64(p.sides-value+1), int64(p.sides-value)
+			}
+			ways := new(big.Int).Exp(big.NewInt(at), number, nil)
+			ways.Sub(ways, new(big.Int).Exp(big.NewInt(beyond), number, nil))
+			d.counts[value-1] = ways
+		}
+		d.total.Exp(big.NewInt(int64(p.sides)), number, nil)
+
+		return d
+	}
+
+	switch {
+	case p.dropLowest && p.dropHighest:
+		d := newDistribution(0, -1)
+		for lowest := 1; lowest <= p.sides; lowest++ {
+			for highest := lowest; highest <= p.sides; highest++ {
+				//inclusion-exclusion leaves only the rolls whose lowest and highest dice are exactly these values
+				ways := sumCounts(p.number, lowest, highest)
+				ways.subtractCounts(sumCounts(p.number, lowest+1, highest))
+				ways.subtractCounts(sumCounts(p.number, lowest, highest-1))
+				ways.addCounts(sumCounts(p.number, lowest+1, highest-1))
+				d.addCounts(ways.shift(-lowest - highest))
+			}
+		}
+		return d
+	case p.dropLowest || p.dropHighest:
+		d := newDistribution(0, -1)
+		for dropped := 1; dropped <= p.sides; dropped++ {
+			var ways *Distribution
+			if p.dropLowest {
+				ways = sumCounts(p.number, dropped, p.sides)
+				ways.subtractCounts(sumCounts(p.number, dropped+1, p.sides))
+			} else {
+				ways = sumCounts(p.number, 1, dropped)
+				ways.subtractCounts(sumCounts(p.number, 1, dropped-1))
+			}
+			d.addCounts(ways.shift(-dropped))
+		}
+		return d
+	}
+
+	return sumCounts(p.number, 1, p.sides)
+}
+
+//exactDistribution computes the exact distribution of the parsed expression.
+//An error is returned if doing so would take too much work.
+func (p parsedExpression) exactDistribution() (*Distribution, error) {
+	operations := p.naturalOperations()
+	naturalSize := float64(p.number)*float64(p.sides) + 1
+	if p.hasSecondExpression {
+		secondSize := float64(p.secondNumber)*float64(p.secondSides) + 1
+		operations += float64(p.secondNumber)*secondSize + naturalSize*secondSize
+	}
+	if operations > maxExactOperations {
+		return nil, ErrDistributionTooLarge
+	}
+
+	return p.finish(p.naturalDistribution()), nil
+}
+
+//finish applies the modifiers, second expression, and result prefixes to a distribution of natural results.
+func (p parsedExpression) finish(natural *Distribution) *Distribution {
+	d := natural
+	if p.operator == "-" {
+		d = d.transform(func(v int) int { return v - p.modifier })
+	} else {
+		d = d.transform(func(v int) int { return v + p.modifier })
+	}
+
+	if p.wantsMax || p.wantsMin {
+		return d
+	}
+
+	if p.hasSecondExpression {
+		second := sumCounts(p.secondNumber, 1, p.secondSides)
+		if p.secondSides == 0 {
+			second = pointDistribution(0)
+		}
+		if p.secondOperator == "-" {
+			second.shift(-p.secondModifier)
+		} else {
+			second.shift(p.secondModifier)
+		}
+		if p.pairOperator == "-" {
+			second = second.transform(func(v int) int { return -v })
+		}
+		d = d.convolve(second)
+	}
+
+	if p.halfResult {
+		return d.transform(func(v int) int { return v / 2 })
+	}
+
+	if p.doubleResult {
+		return d.transform(func(v int) int { return v * 2 })
+	}
+
+	return d
+}
+
+//distribution returns the exact distribution of the parsed expression when it can be computed,
+//otherwise the distribution is estimated by simulation.
+func (p parsedExpression) distribution() *Distribution {
+	d, err := p.exactDistribution()
+	if err == nil {
+		return d
+	}
+
+	sim, _ := p.simulate(context.Background(), p.simulationTrials(), SimulationOptions{})
+
+	return sim.Distribution()
+}
+
+//simulationTrials returns how many times to roll the parsed expression when estimating its distribution,
+//fewer rolls are made the more dice each roll needs so the work stays within maxSimulatedDice.
+func (p parsedExpression) simulationTrials() int {
+	dice := float64(p.number) + 1
+	if p.hasSecondExpression {
+		dice += float64(p.secondNumber)
+	}
+
+	trials := int(maxSimulatedDice / dice)
+
+	return max(min(trials, defaultSimulationTrials), minSimulationTrials)
+}
@@ -0,0 +1,219 @@
+package dice
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func Test_ExactDistribution(t *testing.T) {
+	testCases := []struct {
+		expression string
+		outcomes   []int
+		mean       *big.Rat
+		check      int
+		checkProb  *big.Rat
+		err        error
+	}{
+		{
+			expression: "d6",
+			outcomes:   []int{1, 2, 3, 4, 5, 6},
+			mean:       big.NewRat(7, 2),
+			check:      4,
+			checkProb:  big.NewRat(1, 6),
+		},
+		{
+			expression: "2d6",
+			outcomes:   []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+			mean:       big.NewRat(7, 1),
+			check:      7,
+			checkProb:  big.NewRat(1, 6),
+		},
+		{
+			expression: "1d20+3",
+			outcomes:   []int{4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23},
+			mean:       big.NewRat(27, 2),
+			check:      23,
+			checkProb:  big.NewRat(1, 20),
+		},
+		{
+			expression: "max:2d20",
+			mean:       big.NewRat(5530, 400),
+			check:      20,
+			checkProb:  big.NewRat(39, 400),
+		},
+		{
+			expression: "min:2d20-1",
+			mean:       big.NewRat(2870-400, 400),
+			check:      0,
+			checkProb:  big.NewRat(39, 400),
+		},
+		{
+			expression: "max:min:2d6",
+			mean:       big.NewRat(161, 36),
+			check:      6,
+			checkProb:  big.NewRat(11, 36),
+		},
+		{
+			expression: "dropL:4d6",
+			mean:       big.NewRat(15869, 1296),
+			check:      18,
+			checkProb:  big.NewRat(21, 1296),
+		},
+		{
+			expression: "dropH:2d4",
+			outcomes:   []int{1, 2, 3, 4},
+			mean:       big.NewRat(30, 16),
+			check:      1,
+			checkProb:  big.NewRat(7, 16),
+		},
+		{
+			expression: "dropL:dropH:3d6",
+			outcomes:   []int{1, 2, 3, 4, 5, 6},
+			mean:       big.NewRat(7, 2),
+			check:      1,
+			checkProb:  big.NewRat(16, 216),
+		},
+		{
+			expression: "d12-d3",
+			mean:       big.NewRat(9, 2),
+			check:      -2,
+			checkProb:  big.NewRat(1, 36),
+		},
+		{
+			expression: "2d4+1-d4-1",
+			mean:       big.NewRat(9, 2),
+			check:      9,
+			checkProb:  big.NewRat(1, 64),
+		},
+		{
+			expression: "dub:1d4",
+			outcomes:   []int{2, 4, 6, 8},
+			mean:       big.NewRat(5, 1),
+			check:      3,
+			checkProb:  big.NewRat(0, 1),
+		},
+		{
+			expression: "half:1d3",
+			outcomes:   []int{0, 1},
+			mean:       big.NewRat(2, 3),
+			check:      1,
+			checkProb:  big.NewRat(2, 3),
+		},
+		{
+			expression: "0d4+3",
+			outcomes:   []int{3},
+			mean:       big.NewRat(3, 1),
+			check:      3,
+			checkProb:  big.NewRat(1, 1),
+		},
+		{
+			expression: "2d0",
+			outcomes:   []int{0},
+			mean:       big.NewRat(0, 1),
+			check:      0,
+			checkProb:  big.NewRat(1, 1),
+		},
+		{
+			expression: "heyo",
+			err:        ErrInvalidRollExpression,
+		},
+		{
+			expression: "dropL:dropH:100d100",
+			err:        ErrDistributionTooLarge,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s", i, tc.expression), func(t *testing.T) {
+			got, err := ExactDistribution(tc.expression)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if !got.Exact() {
+				t.Error("[exact] want true, got false")
+			}
+
+			if tc.outcomes != nil && !reflect.DeepEqual(got.Outcomes(), tc.outcomes) {
+				t.Errorf("[outcomes] want %v, got %v", tc.outcomes, got.Outcomes())
+			}
+
+			if got.ExactMean().Cmp(tc.mean) != 0 {
+				t.Errorf("[mean] want %s, got %s", tc.mean.RatString(), got.ExactMean().RatString())
+			}
+
+			if got.ExactProbability(tc.check).Cmp(tc.checkProb) != 0 {
+				t.Errorf("[probability] want %s, got %s", tc.checkProb.RatString(), got.ExactProbability(tc.check).RatString())
+			}
+
+			if got.ExactCumulative(got.Max()).Cmp(big.NewRat(1, 1)) != 0 {
+				t.Errorf("[cumulative] want 1, got %s", got.ExactCumulative(got.Max()).RatString())
+			}
+		})
+	}
+}
+
+func TestDistribution_Percentile(t *testing.T) {
+	testCases := []struct {
+		percent float64
+		want    int
+		err     error
+	}{
+		{percent: 0, want: 1},
+		{percent: 10, want: 1},
+		{percent: 10.1, want: 2},
+		{percent: 50, want: 5},
+		{percent: 90, want: 9},
+		{percent: 100, want: 10},
+		{percent: -1, err: ErrInvalidPercentile},
+		{percent: 101, err: ErrInvalidPercentile},
+	}
+
+	subject, err := ExactDistribution("d10")
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err)
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %v", i, tc.percent), func(t *testing.T) {
+			got, err := subject.Percentile(tc.percent)
+			if got != tc.want {
+				t.Errorf("want %d, got %d", tc.want, got)
+			}
+
+			if err != tc.err {
+				t.Errorf("[err] want %s, got %s", tc.err, err)
+			}
+		})
+	}
+}
+
+func Test_simulationTrials(t *testing.T) {
+	testCases := map[string]struct {
+		expression string
+		want       int
+	}{
+		"few dice use every trial":  {expression: "dropL:dropH:100d100", want: defaultSimulationTrials},
+		"many dice use fewer":       {expression: "1999d6", want: 10000},
+		"second expression counted": {expression: "999d6+1000d6", want: 10000},
+		"huge expressions use few":  {expression: "1000000d6", want: minSimulationTrials},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := parseExpression(tc.expression)
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+
+			if got := parsed.simulationTrials(); got != tc.want {
+				t.Errorf("want %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
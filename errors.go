@@ -11,4 +11,23 @@ var (
 	ErrInvalidOperator       = Error("invalid operator")
 	ErrInvalidNumberOfDice   = Error("invalid number of dice")
 	ErrInvalidNumberOfSides  = Error("invalid number of sides")
+	ErrDistributionTooLarge  = Error("expression is too large to compute exactly")
+	ErrInvalidPercentile     = Error("percentile must be between 0 and 100")
+	ErrInvalidTrials         = Error("trials must be greater than zero")
+	ErrNothingToRender       = Error("there are no results to render")
+	ErrInvalidRange          = Error("range must not be negative")
+	ErrInvalidDistribution   = Error("not a valid distribution")
+	ErrDuplicateDice         = Error("dice name is used more than once")
+	ErrUnsupportedVersion    = Error("unsupported format version")
+	ErrCyclicReference       = Error("dice references itself")
+	ErrDiceInUse             = Error("dice is referenced by other dice")
+	ErrMissingReference      = Error("dice references a dice that does not exist")
+	ErrCyclicLayers          = Error("set cannot inherit from itself")
+	ErrNoRolls               = Error("dice has not been rolled")
+	ErrInvalidDiceLine       = Error("line must be in the form name: expression")
+	ErrNotWritableAsText     = Error("dice cannot be written as text")
+	ErrInvalidShareCode      = Error("not a valid share code")
+	ErrContestTied           = Error("contest is still tied after rerolling")
+	ErrInvalidThreshold      = Error("threshold must be greater than zero")
+	ErrChallengeFinished     = Error("challenge is already finished")
 )
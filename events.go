@@ -0,0 +1,150 @@
+package dice
+
+import (
+	"context"
+	"sync"
+)
+
+//maxQueuedEvents is the most events queued for a subscriber before the oldest are dropped.
+const maxQueuedEvents = 1024
+
+//EventType identifies what changed in a set.
+type EventType int
+
+const (
+	//EventAdded is sent when a new dice is added to a set.
+	EventAdded EventType = iota + 1
+	//EventRemoved is sent when a dice is removed from a set.
+	EventRemoved
+	//EventRolled is sent when a dice in a set is rolled.
+	EventRolled
+	//EventReplaced is sent when a dice in a set is replaced.
+	EventReplaced
+	//EventRenamed is sent when a dice in a set is renamed.
+	EventRenamed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdded:
+		return "added"
+	case EventRemoved:
+		return "removed"
+	case EventRolled:
+		return "rolled"
+	case EventReplaced:
+		return "replaced"
+	case EventRenamed:
+		return "renamed"
+	}
+
+	return "unknown"
+}
+
+//Event describes a change to a set. Events are numbered in the order the changes were made.
+type Event struct {
+	Sequence uint64
+	Type     EventType
+	Name     string
+	Entry    Entry      //the dice after it was added, replaced, or rolled, or before it was removed
+	Previous Entry      //the dice before it was replaced or renamed
+	Roll     RollRecord //the results when rolled
+	Dropped  int        //events dropped just before this one because the subscriber fell behind
+}
+
+//subscriber queues events so publishing never waits on a slow reader.
+type subscriber struct {
+	m       sync.Mutex
+	queue   []Event
+	dropped int //events dropped since the last event was taken from the queue
+	signal  chan struct{}
+}
+
+//Subscribe returns a channel that receives every change made to the set until ctx is done, then the channel is closed.
+//Events arrive in the order the changes and rolls were made, a roll is always announced before any change made after
+//the snapshot it was rolled from. To keep that order rolls are announced one at a time while anyone is subscribed.
+//
+//Events are queued for each subscriber so changing or rolling the set never waits on a slow reader. A reader that falls
+//more than 1024 events behind loses the oldest events, the next event it receives has Dropped set to how many were lost.
+func (s *Set) Subscribe(ctx context.Context) <-chan Event {
+	sub := &subscriber{signal: make(chan struct{}, 1)}
+
+	s.em.Lock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[*subscriber]bool)
+	}
+	s.subscribers[sub] = true
+	s.subscribed.Add(1)
+	s.em.Unlock()
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer func() {
+			s.em.Lock()
+			delete(s.subscribers, sub)
+			s.subscribed.Add(-1)
+			s.em.Unlock()
+		}()
+
+		for {
+			sub.m.Lock()
+			if len(sub.queue) == 0 {
+				sub.m.Unlock()
+				select {
+				case <-ctx.Done():
+					return
+				case <-sub.signal:
+					continue
+				}
+			}
+			event := sub.queue[0]
+			sub.queue = sub.queue[1:]
+			event.Dropped, sub.dropped = sub.dropped, 0
+			sub.m.Unlock()
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
+
+//publish numbers the event and queues it for every subscriber. The caller must hold the event lock, see update and rolling.
+func (s *Set) publish(event Event) {
+	s.sequence++
+	if len(s.subscribers) == 0 {
+		return
+	}
+
+	event.Sequence = s.sequence
+	event.Entry = event.Entry.copy()
+	event.Previous = event.Previous.copy()
+	for sub := range s.subscribers {
+		sub.m.Lock()
+		if len(sub.queue) >= maxQueuedEvents {
+			sub.queue = sub.queue[1:]
+			sub.dropped++
+		}
+		sub.queue = append(sub.queue, event)
+		sub.m.Unlock()
+
+		select {
+		case sub.signal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+//putEvent returns the event for storing entry, previous is the entry it replaced if existed is true.
+func putEvent(entry Entry, previous Entry, existed bool) Event {
+	if existed {
+		return Event{Type: EventReplaced, Name: entry.Name, Entry: entry, Previous: previous}
+	}
+
+	return Event{Type: EventAdded, Name: entry.Name, Entry: entry}
+}
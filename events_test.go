@@ -0,0 +1,236 @@
+package dice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSet_Subscribe(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		subject := NewSet(map[string]string{"initiative": "1d1+2"})
+		events := subject.Subscribe(ctx)
+
+		_ = subject.AddDice("damage", "2d1")
+		_ = subject.AddDice("damage", "3d1")
+		_, _, _ = subject.RollDice("damage")
+		_ = subject.RemoveDice("damage")
+		_ = subject.RemoveDice("missing")
+
+		want := []EventType{EventAdded, EventReplaced, EventRolled, EventRemoved}
+		var sequence uint64
+		for i, wantType := range want {
+			event := receive(t, events)
+			if event.Type != wantType || event.Name != "damage" {
+				t.Errorf("[%d] want %s damage, got %s %s", i, wantType, event.Type, event.Name)
+			}
+			if event.Sequence <= sequence {
+				t.Errorf("[%d] sequence %d does not follow %d", i, event.Sequence, sequence)
+			}
+			sequence = event.Sequence
+
+			switch event.Type {
+			case EventReplaced:
+				if event.Previous.Expression != "2d1" || event.Entry.Expression != "3d1" {
+					t.Errorf("[replaced] want 2d1 -> 3d1, got %s -> %s", event.Previous.Expression, event.Entry.Expression)
+				}
+			case EventRolled:
+				if event.Roll.Sum != 3 || len(event.Roll.Rolls) != 3 {
+					t.Errorf("[rolled] unexpected roll %v", event.Roll)
+				}
+			case EventRemoved:
+				if event.Entry.Expression != "3d1" {
+					t.Errorf("[removed] want 3d1, got %s", event.Entry.Expression)
+				}
+			}
+		}
+
+		select {
+		case event := <-events:
+			t.Errorf("unexpected event %v", event)
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+
+	t.Run("cancelling closes the channel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		subject := NewSet(map[string]string{"d6": "1d6"})
+		events := subject.Subscribe(ctx)
+		cancel()
+
+		select {
+		case _, ok := <-events:
+			if ok {
+				//an event may only be received if one was queued, none were
+				t.Errorf("unexpected event after cancel")
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("channel was not closed")
+		}
+
+		//changes after cancelling must not block
+		_ = subject.AddDice("d8", "1d8")
+	})
+
+	t.Run("slow readers do not block changes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		subject := NewSet(map[string]string{"d6": "1d6"})
+		events := subject.Subscribe(ctx)
+		for i := 0; i < 1000; i++ {
+			_, _, _ = subject.RollDice("d6")
+		}
+
+		for i := 0; i < 1000; i++ {
+			if event := receive(t, events); event.Type != EventRolled {
+				t.Fatalf("[%d] want rolled, got %s", i, event.Type)
+			}
+		}
+	})
+
+	t.Run("readers that fall too far behind lose the oldest events", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		subject := NewSet(map[string]string{"d6": "1d6"})
+		events := subject.Subscribe(ctx)
+		total := maxQueuedEvents + 100
+		for i := 0; i < total; i++ {
+			_, _, _ = subject.RollDice("d6")
+		}
+
+		received, dropped := 0, 0
+		var last uint64
+		for received+dropped < total {
+			event := receive(t, events)
+			if last > 0 && event.Sequence != last+uint64(event.Dropped)+1 {
+				t.Fatalf("sequence %d does not follow %d after dropping %d", event.Sequence, last, event.Dropped)
+			}
+			last = event.Sequence
+			received++
+			dropped += event.Dropped
+		}
+
+		if received > maxQueuedEvents+1 {
+			t.Errorf("want at most %d events kept, got %d", maxQueuedEvents+1, received)
+		}
+		if dropped == 0 {
+			t.Error("want events dropped, got none")
+		}
+	})
+
+	t.Run("loading sends changes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		source := NewSet(map[string]string{"d6": "2d6", "d8": "1d8"})
+		var b bytes.Buffer
+		_ = source.Save(&b)
+
+		subject := NewSet(map[string]string{"d6": "1d6", "d4": "1d4"})
+		events := subject.Subscribe(ctx)
+		if err := subject.Load(&b); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		want := []Event{{Type: EventRemoved, Name: "d4"}, {Type: EventReplaced, Name: "d6"}, {Type: EventAdded, Name: "d8"}}
+		for i, w := range want {
+			if event := receive(t, events); event.Type != w.Type || event.Name != w.Name {
+				t.Errorf("[%d] want %s %s, got %s %s", i, w.Type, w.Name, event.Type, event.Name)
+			}
+		}
+	})
+}
+
+func Test_race_events(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subject := NewSet(map[string]string{"d6": "1d6"})
+	events := subject.Subscribe(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = subject.AddDice("d8", "1d8")
+			_, _, _ = subject.RollDice("d6")
+			_ = subject.RemoveDice("d8")
+			_ = subject.Subscribe(ctx)
+		}()
+	}
+	wg.Wait()
+
+	var sequence uint64
+	for i := 0; i < 20; i++ { //every add and roll sends an event, removes may find nothing to remove
+		event := receive(t, events)
+		if event.Sequence <= sequence {
+			t.Errorf("[%d] sequence %d does not follow %d", i, event.Sequence, sequence)
+		}
+		sequence = event.Sequence
+	}
+}
+
+func Test_race_event_order(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subject := NewSet(map[string]string{"weapon": "500d1"})
+	events := subject.Subscribe(ctx)
+
+	const changes, rollers, rolls = 100, 8, 100
+	var wg sync.WaitGroup
+	for i := 0; i < rollers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rolls; j++ {
+				_, _, _ = subject.RollDice("weapon")
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < changes; i++ {
+			_ = subject.AddDice("weapon", fmt.Sprintf("%dd1", (i%2+1)*500))
+			time.Sleep(10 * time.Microsecond)
+		}
+	}()
+
+	//every roll must carry the expression of the latest change announced before it
+	expression := "500d1"
+	for i := 0; i < changes+rollers*rolls; i++ {
+		event := receive(t, events)
+		switch event.Type {
+		case EventReplaced:
+			expression = event.Entry.Expression
+		case EventRolled:
+			if event.Entry.Expression != expression {
+				t.Fatalf("[%d] roll of %s announced after change to %s", event.Sequence, event.Entry.Expression, expression)
+			}
+		}
+	}
+	wg.Wait()
+}
+
+func receive(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for an event")
+	}
+
+	return Event{}
+}
@@ -0,0 +1,214 @@
+package dice
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+//NumberFormat selects how probabilities are written when encoding a distribution.
+type NumberFormat int
+
+const (
+	//FloatFormat writes probabilities as decimal numbers, e.g. 0.027777777777777776.
+	FloatFormat NumberFormat = iota
+	//RationalFormat writes probabilities as exact fractions, e.g. 1/36.
+	RationalFormat
+)
+
+//maxReadRange caps the span between the lowest and highest values of a distribution being read,
+//every value in between is stored so a wider span could use far too much memory.
+const maxReadRange = 1 << 22
+
+var distributionCSVHeader = []string{"value", "probability", "cumulative", "exceedance"}
+
+type distributionJSON struct {
+	Exact    bool          `json:"exact"`
+	Outcomes []outcomeJSON `json:"outcomes"`
+}
+
+type outcomeJSON struct {
+	Value       int             `json:"value"`
+	Probability json.RawMessage `json:"probability"`
+	Cumulative  json.RawMessage `json:"cumulative"`
+	Exceedance  json.RawMessage `json:"exceedance"`
+}
+
+//WriteDistributionCSV writes a row to w for every result of the distribution with its probability,
+//cumulative probability (rolling the value or lower), and exceedance (rolling the value or higher).
+func WriteDistributionCSV(w io.Writer, d *Distribution, format NumberFormat) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(distributionCSVHeader); err != nil {
+		return err
+	}
+
+	for _, row := range distributionRows(d) {
+		record := []string{strconv.Itoa(row.value), formatRat(row.probability, format), formatRat(row.cumulative, format), formatRat(row.exceedance, format)}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+//ReadDistributionCSV reads a distribution written by WriteDistributionCSV. The distribution is only
+//exact if every probability was written as a fraction and they add up to one.
+//
+//An error is returned if the data is not a valid distribution, or its values span more than 4,194,304 results.
+func ReadDistributionCSV(r io.Reader) (*Distribution, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 || strings.Join(records[0], ",") != strings.Join(distributionCSVHeader, ",") {
+		return nil, ErrInvalidDistribution
+	}
+
+	probabilities := make(map[int]string)
+	for _, record := range records[1:] {
+		value, err := strconv.Atoi(record[0])
+		if err != nil {
+			return nil, ErrInvalidDistribution
+		}
+		if _, exists := probabilities[value]; exists {
+			return nil, ErrInvalidDistribution
+		}
+		probabilities[value] = record[1]
+	}
+
+	return distributionFromProbabilities(probabilities, true)
+}
+
+//WriteDistributionJSON writes the distribution to w as a JSON object listing every result with its probability,
+//cumulative probability (rolling the value or lower), and exceedance (rolling the value or higher).
+//Fractions are written as strings, decimals as numbers.
+func WriteDistributionJSON(w io.Writer, d *Distribution, format NumberFormat) error {
+	encoded := distributionJSON{Exact: d.Exact(), Outcomes: []outcomeJSON{}}
+	for _, row := range distributionRows(d) {
+		encoded.Outcomes = append(encoded.Outcomes, outcomeJSON{
+			Value:       row.value,
+			Probability: jsonRat(row.probability, format),
+			Cumulative:  jsonRat(row.cumulative, format),
+			Exceedance:  jsonRat(row.exceedance, format),
+		})
+	}
+
+	return json.NewEncoder(w).Encode(encoded)
+}
+
+//ReadDistributionJSON reads a distribution written by WriteDistributionJSON. The distribution is only
+//exact if it was written as exact, every probability was written as a fraction, and they add up to one.
+//
+//An error is returned if the data is not a valid distribution, or its values span more than 4,194,304 results.
+func ReadDistributionJSON(r io.Reader) (*Distribution, error) {
+	var decoded distributionJSON
+	if err := json.NewDecoder(r).Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	probabilities := make(map[int]string)
+	for _, outcome := range decoded.Outcomes {
+		if _, exists := probabilities[outcome.Value]; exists {
+			return nil, ErrInvalidDistribution
+		}
+		probability := string(outcome.Probability)
+		if unquoted, err := strconv.Unquote(probability); err == nil {
+			probability = unquoted
+		}
+		probabilities[outcome.Value] = probability
+	}
+
+	return distributionFromProbabilities(probabilities, decoded.Exact)
+}
+
+type distributionRow struct {
+	value       int
+	probability *big.Rat
+	cumulative  *big.Rat
+	exceedance  *big.Rat
+}
+
+//distributionRows returns the probability, cumulative probability, and exceedance of every result.
+func distributionRows(d *Distribution) []distributionRow {
+	var rows []distributionRow
+	cumulative := new(big.Rat)
+	for _, value := range d.Outcomes() {
+		probability := d.ExactProbability(value)
+		exceedance := new(big.Rat).Sub(big.NewRat(1, 1), cumulative)
+		cumulative = new(big.Rat).Add(cumulative, probability)
+		rows = append(rows, distributionRow{value: value, probability: probability, cumulative: cumulative, exceedance: exceedance})
+	}
+
+	return rows
+}
+
+func formatRat(r *big.Rat, format NumberFormat) string {
+	if format == RationalFormat {
+		return r.RatString()
+	}
+
+	f, _ := r.Float64()
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func jsonRat(r *big.Rat, format NumberFormat) json.RawMessage {
+	if format == RationalFormat {
+		return json.RawMessage(strconv.Quote(r.RatString()))
+	}
+
+	return json.RawMessage(formatRat(r, format))
+}
+
+//distributionFromProbabilities rebuilds a distribution from the probability of each value.
+func distributionFromProbabilities(probabilities map[int]string, exact bool) (*Distribution, error) {
+	if len(probabilities) == 0 {
+		return nil, ErrInvalidDistribution
+	}
+
+	parsed := make(map[int]*big.Rat)
+	denominator := big.NewInt(1)
+	low, high := 0, 0
+	first := true
+	for value, text := range probabilities {
+		probability, ok := new(big.Rat).SetString(text)
+		if !ok || probability.Sign() < 0 {
+			return nil, ErrInvalidDistribution
+		}
+		//fractions are required for the result to be exact
+		exact = exact && !strings.ContainsAny(text, ".eE")
+
+		parsed[value] = probability
+		gcd := new(big.Int).GCD(nil, nil, denominator, probability.Denom())
+		denominator.Mul(denominator, new(big.Int).Quo(probability.Denom(), gcd))
+
+		if first {
+			low, high = value, value
+			first = false
+		}
+		low, high = min(low, value), max(high, value)
+	}
+
+	if int64(high)-int64(low) >= maxReadRange {
+		return nil, ErrInvalidDistribution
+	}
+
+	d := newDistribution(low, high)
+	for value, probability := range parsed {
+		count := new(big.Int).Mul(probability.Num(), new(big.Int).Quo(denominator, probability.Denom()))
+		d.counts[value-low] = count
+		d.total.Add(d.total, count)
+	}
+
+	if d.total.Sign() == 0 {
+		return nil, ErrInvalidDistribution
+	}
+	d.exact = exact && d.total.Cmp(denominator) == 0
+
+	return d, nil
+}
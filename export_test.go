@@ -0,0 +1,235 @@
+package dice
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_WriteDistributionCSV(t *testing.T) {
+	testCases := []struct {
+		scenario string
+		format   NumberFormat
+		want     string
+	}{
+		{
+			scenario: "rational",
+			format:   RationalFormat,
+			want:     "value,probability,cumulative,exceedance\n1,1/4,1/4,1\n2,1/2,3/4,3/4\n3,1/4,1,1/4\n",
+		},
+		{
+			scenario: "float",
+			format:   FloatFormat,
+			want:     "value,probability,cumulative,exceedance\n1,0.25,0.25,1\n2,0.5,0.75,0.75\n3,0.25,1,0.25\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			d, err := ExactDistribution("2d2-1")
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+
+			var got bytes.Buffer
+			err = WriteDistributionCSV(&got, d, tc.format)
+			if err != nil {
+				t.Errorf("unexpected error, %s", err)
+			}
+
+			if got.String() != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got.String())
+			}
+		})
+	}
+}
+
+func Test_ReadDistributionCSV(t *testing.T) {
+	testCases := []struct {
+		scenario string
+		data     string
+		outcomes []int
+		exact    bool
+		err      error
+	}{
+		{
+			scenario: "rational",
+			data:     "value,probability,cumulative,exceedance\n1,1/4,1/4,1\n2,1/2,3/4,3/4\n3,1/4,1,1/4\n",
+			outcomes: []int{1, 2, 3},
+			exact:    true,
+		},
+		{
+			scenario: "float",
+			data:     "value,probability,cumulative,exceedance\n-1,0.25,0.25,1\n1,0.75,1,0.75\n",
+			outcomes: []int{-1, 1},
+			exact:    false,
+		},
+		{
+			scenario: "missing header",
+			data:     "1,1/4,1/4,1\n",
+			err:      ErrInvalidDistribution,
+		},
+		{
+			scenario: "invalid value",
+			data:     "value,probability,cumulative,exceedance\none,1/4,1/4,1\n",
+			err:      ErrInvalidDistribution,
+		},
+		{
+			scenario: "invalid probability",
+			data:     "value,probability,cumulative,exceedance\n1,-1/4,1/4,1\n",
+			err:      ErrInvalidDistribution,
+		},
+		{
+			scenario: "duplicate value",
+			data:     "value,probability,cumulative,exceedance\n1,1/2,1/2,1\n1,1/2,1,1/2\n",
+			err:      ErrInvalidDistribution,
+		},
+		{
+			scenario: "values too far apart",
+			data:     "value,probability,cumulative,exceedance\n0,1/2,1/2,1\n2000000000,1/2,1,1/2\n",
+			err:      ErrInvalidDistribution,
+		},
+		{
+			scenario: "no values",
+			data:     "value,probability,cumulative,exceedance\n",
+			err:      ErrInvalidDistribution,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			got, err := ReadDistributionCSV(strings.NewReader(tc.data))
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(got.Outcomes(), tc.outcomes) {
+				t.Errorf("[outcomes] want %v, got %v", tc.outcomes, got.Outcomes())
+			}
+			if got.Exact() != tc.exact {
+				t.Errorf("[exact] want %t, got %t", tc.exact, got.Exact())
+			}
+		})
+	}
+}
+
+func Test_DistributionRoundTrip(t *testing.T) {
+	formats := map[string]NumberFormat{"rational": RationalFormat, "float": FloatFormat}
+	for name, format := range formats {
+		t.Run("csv "+name, func(t *testing.T) {
+			want, err := ExactDistribution("3d6-1d4")
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDistributionCSV(&buf, want, format); err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			got, err := ReadDistributionCSV(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+
+			assertSameDistribution(t, want, got, format == RationalFormat)
+		})
+
+		t.Run("json "+name, func(t *testing.T) {
+			want, err := ExactDistribution("dropL:4d6")
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDistributionJSON(&buf, want, format); err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+			got, err := ReadDistributionJSON(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error, %s", err)
+			}
+
+			assertSameDistribution(t, want, got, format == RationalFormat)
+		})
+	}
+
+	t.Run("json keeps simulations estimated", func(t *testing.T) {
+		sim, err := Simulate(context.Background(), "2d6", 1000, SimulationOptions{Seed: 9})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteDistributionJSON(&buf, sim.Distribution(), RationalFormat); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		got, err := ReadDistributionJSON(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if got.Exact() {
+			t.Error("[exact] want false, got true")
+		}
+	})
+}
+
+func Test_ReadDistributionJSON(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		got, err := ReadDistributionJSON(strings.NewReader(`{"exact":true,"outcomes":[{"value":0,"probability":"1/3"},{"value":5,"probability":"2/3"}]}`))
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if got.Mean() != 10.0/3 {
+			t.Errorf("[mean] want %f, got %f", 10.0/3, got.Mean())
+		}
+		if !got.Exact() {
+			t.Error("[exact] want true, got false")
+		}
+	})
+
+	t.Run("error when probability is invalid", func(t *testing.T) {
+		_, err := ReadDistributionJSON(strings.NewReader(`{"exact":true,"outcomes":[{"value":1,"probability":"heyo"}]}`))
+		if err != ErrInvalidDistribution {
+			t.Errorf("want %s, got %s", ErrInvalidDistribution, err)
+		}
+	})
+
+	t.Run("error when value is repeated", func(t *testing.T) {
+		_, err := ReadDistributionJSON(strings.NewReader(`{"exact":true,"outcomes":[{"value":1,"probability":0.5},{"value":1,"probability":0.5}]}`))
+		if err != ErrInvalidDistribution {
+			t.Errorf("want %s, got %s", ErrInvalidDistribution, err)
+		}
+	})
+
+	t.Run("error when values are too far apart", func(t *testing.T) {
+		_, err := ReadDistributionJSON(strings.NewReader(`{"exact":true,"outcomes":[{"value":-2000000000,"probability":"1/2"},{"value":2000000000,"probability":"1/2"}]}`))
+		if err != ErrInvalidDistribution {
+			t.Errorf("want %s, got %s", ErrInvalidDistribution, err)
+		}
+	})
+}
+
+func assertSameDistribution(t *testing.T, want, got *Distribution, exact bool) {
+	t.Helper()
+	if !reflect.DeepEqual(got.Outcomes(), want.Outcomes()) {
+		t.Errorf("[outcomes] want %v, got %v", want.Outcomes(), got.Outcomes())
+	}
+	if got.Exact() != exact {
+		t.Errorf("[exact] want %t, got %t", exact, got.Exact())
+	}
+	for _, value := range want.Outcomes() {
+		if exact && got.ExactProbability(value).Cmp(want.ExactProbability(value)) != 0 {
+			t.Errorf("[probability %d] want %s, got %s", value, want.ExactProbability(value).RatString(), got.ExactProbability(value).RatString())
+		}
+		if !exact && got.Probability(value) != want.Probability(value) {
+			t.Errorf("[probability %d] want %v, got %v", value, want.Probability(value), got.Probability(value))
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -31,92 +32,133 @@ func ContainsValidRollExpression(data string) int {
 //An error is returned if the expression is invalid. The min: and max: can cause
 //an error if they are used with an expression pair.
 func RollExpression(expression string) ([]int, int, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rolls, _, sum := parsed.roll(New(time.Now().UnixNano()))
+
+	return rolls, sum, nil
+}
+
+//parsedExpression holds the pieces of a roll expression once its prefixes and optional pair have been read.
+type parsedExpression struct {
+	wantsMax, wantsMin, halfResult, doubleResult, dropLowest, dropHighest bool
+
+	number   int
+	sides    int
+	operator string
+	modifier int
+
+	hasSecondExpression bool
+	pairOperator        string
+	secondNumber        int
+	secondSides         int
+	secondOperator      string
+	secondModifier      int
+}
+
+//parseExpression validates and breaks apart a roll expression so it can be rolled or analyzed.
+func parseExpression(expression string) (parsedExpression, error) {
+	var p parsedExpression
 	//check for a special prefix
-	var wantsMax, wantsMin, halfResult, doubleResult, dropLowest, dropHighest bool
 	if strings.HasPrefix(expression, "max:") {
-		wantsMax = true
+		p.wantsMax = true
 		expression = strings.ReplaceAll(expression, "max:", "")
 	}
 
 	if strings.HasPrefix(expression, "min:") {
-		wantsMin = true
+		p.wantsMin = true
 		expression = strings.ReplaceAll(expression, "min:", "")
 	}
 
 	if strings.HasPrefix(expression, "half:") {
-		halfResult = true
+		p.halfResult = true
 		expression = strings.ReplaceAll(expression, "half:", "")
 	}
 
 	if strings.HasPrefix(expression, "dub:") {
-		doubleResult = true
+		p.doubleResult = true
 		expression = strings.ReplaceAll(expression, "dub:", "")
 	}
 
 	if strings.HasPrefix(expression, "dropL:") {
-		dropLowest = true
+		p.dropLowest = true
 		expression = strings.ReplaceAll(expression, "dropL:", "")
 	}
 
 	if strings.HasPrefix(expression, "dropH:") {
-		dropHighest = true
+		p.dropHighest = true
 		expression = strings.ReplaceAll(expression, "dropH:", "")
 	}
 
 	//simple 1d4+1 style (#d#+|-# or #d# or d#)
 	if !ValidRollExpression(expression) {
-		return nil, 0, ErrInvalidRollExpression
+		return p, ErrInvalidRollExpression
 	}
 
-	hasSecondExpression := false
 	match := RollExpressionRE.FindStringSubmatch(expression)
 	if match[5] != "" {
-		hasSecondExpression = true
+		p.hasSecondExpression = true
 	}
 
 	//min: and max: prefix is not valid if expression is a pair/double expression.
-	if hasSecondExpression && (wantsMax || wantsMin) {
-		return nil, 0, ErrInvalidRollExpression
+	if p.hasSecondExpression && (p.wantsMax || p.wantsMin) {
+		return p, ErrInvalidRollExpression
 	}
 
-	number, _ := strconv.Atoi(match[1])
+	p.number, _ = strconv.Atoi(match[1])
 	//convert the absence of a number to mean 1 to satisfy d6 like shorthand, otherwise it was a 0
-	if number == 0 && match[1] == "" {
-		number = 1
+	if p.number == 0 && match[1] == "" {
+		p.number = 1
 	}
-	sides, _ := strconv.Atoi(match[2])
+	p.sides, _ = strconv.Atoi(match[2])
+	p.operator = match[3]
+	p.modifier, _ = strconv.Atoi(match[4])
 
-	//handle min and max here
-	if wantsMax {
-		rolls, sum, _ := RollMax(number, sides)
-		if match[3] != "" {
-			modifier, _ := strconv.Atoi(match[4])
-			sum, _ = Modify(sum, match[3], modifier)
+	if p.hasSecondExpression {
+		p.pairOperator = match[6]
+		p.secondNumber, _ = strconv.Atoi(match[7])
+		//convert the absence of a number to mean 1 to satisfy d6 like shorthand, otherwise it was a 0
+		if p.secondNumber == 0 && match[7] == "" {
+			p.secondNumber = 1
 		}
-		return rolls, sum, nil
+		p.secondSides, _ = strconv.Atoi(match[8])
+		p.secondOperator = match[9]
+		p.secondModifier, _ = strconv.Atoi(match[10])
 	}
 
-	if wantsMin {
-		rolls, sum, _ := RollMin(number, sides)
-		if match[3] != "" {
-			modifier, _ := strconv.Atoi(match[4])
-			sum, _ = Modify(sum, match[3], modifier)
-		}
-		return rolls, sum, nil
-	}
+	return p, nil
+}
 
-	//anything after this comment uses the standard roll or roll modify
-	var rolls []int
-	var sum int
-	if match[3] == "" {
-		rolls, sum, _ = Roll(number, sides)
-	} else {
-		modifier, _ := strconv.Atoi(match[4])
-		rolls, _, sum, _ = RollAndModify(number, sides, match[3], modifier)
+//roll rolls the parsed expression using the provided seeder. Along with the rolls and final sum it returns
+//the natural result, which is the value of the first expression's dice before any modifiers are applied.
+func (p parsedExpression) roll(s *seeder) (rolls []int, natural int, sum int) {
+	rolls, natural = s.rollDice(p.number, p.sides)
+
+	//handle min and max here
+	if p.wantsMax || p.wantsMin {
+		natural = 0
+		for r, roll := range rolls {
+			switch {
+			case r == 0:
+				natural = roll
+			case p.wantsMax:
+				natural = max(natural, roll)
+			default:
+				natural = min(natural, roll)
+			}
+		}
+		sum = natural
+		if p.operator != "" {
+			sum, _ = Modify(sum, p.operator, p.modifier)
+		}
+		return rolls, natural, sum
 	}
 
 	//the drop prefixes only apply to the first expression, the second expression is treated like a modifier
-	if dropLowest {
+	if p.dropLowest {
 		lowest := 0
 		for r, roll := range rolls {
 			if r == 0 {
@@ -125,10 +167,10 @@ func RollExpression(expression string) ([]int, int, error) {
 			}
 			lowest = min(lowest, roll)
 		}
-		sum = sum - lowest
+		natural -= lowest
 	}
 
-	if dropHighest {
+	if p.dropHighest {
 		highest := 0
 		for r, roll := range rolls {
 			if r == 0 {
@@ -137,28 +179,22 @@ func RollExpression(expression string) ([]int, int, error) {
 			}
 			highest = max(highest, roll)
 		}
-		sum = sum - highest
+		natural -= highest
 	}
 
-	//let's handle second expression if provided
-	if hasSecondExpression {
-		var secondRolls []int
-		var secondSum int
+	sum = natural
+	if p.operator != "" {
+		sum, _ = Modify(sum, p.operator, p.modifier)
+	}
 
-		secondNumber, _ := strconv.Atoi(match[7])
-		//convert the absence of a number to mean 1 to satisfy d6 like shorthand, otherwise it was a 0
-		if secondNumber == 0 && match[7] == "" {
-			secondNumber = 1
-		}
-		secondSides, _ := strconv.Atoi(match[8])
-		if match[9] == "" {
-			secondRolls, secondSum, _ = Roll(secondNumber, secondSides)
-		} else {
-			secondModifier, _ := strconv.Atoi(match[10])
-			secondRolls, _, secondSum, _ = RollAndModify(secondNumber, secondSides, match[9], secondModifier)
+	//let's handle second expression if provided
+	if p.hasSecondExpression {
+		secondRolls, secondSum := s.rollDice(p.secondNumber, p.secondSides)
+		if p.secondOperator != "" {
+			secondSum, _ = Modify(secondSum, p.secondOperator, p.secondModifier)
 		}
 
-		switch match[6] {
+		switch p.pairOperator {
 		case "-":
 			sum -= secondSum
 		case "+":
@@ -168,17 +204,15 @@ func RollExpression(expression string) ([]int, int, error) {
 		rolls = append(rolls, secondRolls...)
 	}
 
-	if halfResult {
-		sum = sum / 2
-		return rolls, sum, nil
+	if p.halfResult {
+		return rolls, natural, sum / 2
 	}
 
-	if doubleResult {
-		sum = sum * 2
-		return rolls, sum, nil
+	if p.doubleResult {
+		return rolls, natural, sum * 2
 	}
 
-	return rolls, sum, nil
+	return rolls, natural, sum
 }
 
 func RollString(value string) string {
@@ -0,0 +1,139 @@
+package dice
+
+import "time"
+
+//ExtendedStatus is how far along an extended challenge is.
+type ExtendedStatus int
+
+const (
+	//ExtendedInProgress is a challenge that can still be rolled.
+	ExtendedInProgress ExtendedStatus = iota
+	//ExtendedSucceeded is a challenge whose progress reached its threshold.
+	ExtendedSucceeded
+	//ExtendedFailed is a challenge that ran out of attempts, or dice, before reaching its threshold.
+	ExtendedFailed
+)
+
+func (s ExtendedStatus) String() string {
+	switch s {
+	case ExtendedInProgress:
+		return "in progress"
+	case ExtendedSucceeded:
+		return "succeeded"
+	case ExtendedFailed:
+		return "failed"
+	}
+
+	return "unknown"
+}
+
+//ExtendedChallenge accumulates progress over several rolls until a threshold is reached or attempts run out,
+//e.g. a Shadowrun extended test, a skill challenge, or a progress clock. Every field is exported so a challenge
+//can be saved, e.g. as JSON, and picked up again in a later session.
+//
+//By default each roll adds its total to the progress. Set SuccessTarget to count rolls that meet it instead,
+//or HitsOn to count dice that roll at or above it.
+type ExtendedChallenge struct {
+	Expression      string            `json:"expression"`
+	Threshold       int               `json:"threshold"`                 //progress needed to succeed
+	MaxAttempts     int               `json:"maxAttempts,omitempty"`     //attempts allowed, 0 allows any number
+	SuccessTarget   int               `json:"successTarget,omitempty"`   //each roll totaling at least this adds one progress, 0 disables
+	HitsOn          int               `json:"hitsOn,omitempty"`          //each dice rolling at least this adds one progress, 0 disables
+	DicePenalty     int               `json:"dicePenalty,omitempty"`     //dice taken from the first group of the expression after every attempt, e.g. 1
+	ModifierPenalty int               `json:"modifierPenalty,omitempty"` //taken from the total after every attempt, e.g. 1
+	Progress        int               `json:"progress"`
+	Attempts        []ExtendedAttempt `json:"attempts,omitempty"`
+}
+
+//ExtendedAttempt is one roll of an extended challenge.
+type ExtendedAttempt struct {
+	Rolls    []int `json:"rolls"`
+	Total    int   `json:"total"`    //total after penalties
+	Progress int   `json:"progress"` //progress added by the roll
+}
+
+//NewExtendedChallenge returns an extended challenge that succeeds once rolling the expression has added up to the threshold,
+//failing if that takes more than maxAttempts rolls. Set the other fields of the challenge to change how progress is made.
+//
+//An error is returned if the expression is not a valid roll expression, or the threshold is less than one.
+func NewExtendedChallenge(expression string, threshold int, maxAttempts int) (*ExtendedChallenge, error) {
+	if _, err := parseExpression(expression); err != nil {
+		return nil, err
+	}
+	if threshold < 1 {
+		return nil, ErrInvalidThreshold
+	}
+
+	return &ExtendedChallenge{Expression: expression, Threshold: threshold, MaxAttempts: maxAttempts}, nil
+}
+
+//Status returns whether the challenge succeeded, failed, or can still be rolled.
+func (c *ExtendedChallenge) Status() ExtendedStatus {
+	switch {
+	case c.Progress >= c.Threshold:
+		return ExtendedSucceeded
+	case c.MaxAttempts > 0 && len(c.Attempts) >= c.MaxAttempts:
+		return ExtendedFailed
+	}
+
+	//penalties can leave no dice to roll
+	if parsed, err := parseExpression(c.Expression); err == nil && c.DicePenalty > 0 && parsed.number-c.DicePenalty*len(c.Attempts) < 1 {
+		return ExtendedFailed
+	}
+
+	return ExtendedInProgress
+}
+
+//Remaining returns the number of attempts left, or -1 if there is no limit.
+func (c *ExtendedChallenge) Remaining() int {
+	if c.MaxAttempts <= 0 {
+		return -1
+	}
+
+	return max(c.MaxAttempts-len(c.Attempts), 0)
+}
+
+//Roll makes the next attempt at the challenge, applying any penalties for earlier attempts, and adds to its progress.
+//
+//An error is returned if the challenge is already finished, or the expression is not a valid roll expression.
+func (c *ExtendedChallenge) Roll() (ExtendedAttempt, error) {
+	return c.roll(New(time.Now().UnixNano()))
+}
+
+//roll makes the next attempt using the provided seeder, see Roll for details.
+func (c *ExtendedChallenge) roll(seeder *seeder) (ExtendedAttempt, error) {
+	parsed, err := parseExpression(c.Expression)
+	if err != nil {
+		return ExtendedAttempt{}, err
+	}
+	if c.Status() != ExtendedInProgress {
+		return ExtendedAttempt{}, ErrChallengeFinished
+	}
+
+	previous := len(c.Attempts)
+	parsed.number -= c.DicePenalty * previous
+
+	var attempt ExtendedAttempt
+	attempt.Rolls, _, attempt.Total = parsed.roll(seeder)
+	attempt.Total -= c.ModifierPenalty * previous
+
+	switch {
+	case c.HitsOn > 0:
+		for _, roll := range attempt.Rolls {
+			if roll >= c.HitsOn {
+				attempt.Progress++
+			}
+		}
+	case c.SuccessTarget > 0:
+		if attempt.Total >= c.SuccessTarget {
+			attempt.Progress = 1
+		}
+	default:
+		attempt.Progress = max(attempt.Total, 0)
+	}
+
+	c.Progress += attempt.Progress
+	c.Attempts = append(c.Attempts, attempt)
+
+	return attempt, nil
+}
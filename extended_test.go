@@ -0,0 +1,172 @@
+package dice
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewExtendedChallenge(t *testing.T) {
+	testCases := []struct {
+		expression  string
+		threshold   int
+		maxAttempts int
+		err         error
+	}{
+		{
+			expression:  "12d6",
+			threshold:   10,
+			maxAttempts: 5,
+		},
+		{
+			expression: "1d20+4",
+			threshold:  6,
+		},
+		{
+			expression: "1d",
+			threshold:  10,
+			err:        ErrInvalidRollExpression,
+		},
+		{
+			expression: "1d6",
+			threshold:  0,
+			err:        ErrInvalidThreshold,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s", i, tc.expression), func(t *testing.T) {
+			got, err := NewExtendedChallenge(tc.expression, tc.threshold, tc.maxAttempts)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+			if got.Status() != ExtendedInProgress {
+				t.Errorf("[status] want %s, got %s", ExtendedInProgress, got.Status())
+			}
+		})
+	}
+}
+
+func TestExtendedChallenge_roll(t *testing.T) {
+	testCases := []struct {
+		challenge ExtendedChallenge
+		progress  []int
+		totals    []int
+		status    ExtendedStatus
+		remaining int
+	}{
+		{
+			challenge: ExtendedChallenge{Expression: "2d1+1", Threshold: 9, MaxAttempts: 4},
+			progress:  []int{3, 3, 3},
+			totals:    []int{3, 3, 3},
+			status:    ExtendedSucceeded,
+			remaining: 1,
+		},
+		{
+			challenge: ExtendedChallenge{Expression: "1d1", Threshold: 5, MaxAttempts: 3},
+			progress:  []int{1, 1, 1},
+			totals:    []int{1, 1, 1},
+			status:    ExtendedFailed,
+			remaining: 0,
+		},
+		{
+			challenge: ExtendedChallenge{Expression: "1d1+4", Threshold: 3, MaxAttempts: 6, SuccessTarget: 4, ModifierPenalty: 1},
+			progress:  []int{1, 1, 0, 0, 0, 0},
+			totals:    []int{5, 4, 3, 2, 1, 0},
+			status:    ExtendedFailed,
+			remaining: 0,
+		},
+		{
+			challenge: ExtendedChallenge{Expression: "3d1", Threshold: 7, HitsOn: 1, DicePenalty: 1},
+			progress:  []int{3, 2, 1},
+			totals:    []int{3, 2, 1},
+			status:    ExtendedFailed,
+			remaining: -1,
+		},
+		{
+			challenge: ExtendedChallenge{Expression: "1d1", Threshold: 3, MaxAttempts: 3, ModifierPenalty: 2},
+			progress:  []int{1, 0, 0},
+			totals:    []int{1, -1, -3},
+			status:    ExtendedFailed,
+			remaining: 0,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s", i, tc.challenge.Expression), func(t *testing.T) {
+			challenge := tc.challenge
+			seeder := New(1)
+
+			var gotProgress, gotTotals []int
+			for challenge.Status() == ExtendedInProgress {
+				attempt, err := challenge.roll(seeder)
+				if err != nil {
+					t.Fatalf("unexpected error %s", err)
+				}
+				gotProgress = append(gotProgress, attempt.Progress)
+				gotTotals = append(gotTotals, attempt.Total)
+			}
+
+			if !reflect.DeepEqual(gotProgress, tc.progress) {
+				t.Errorf("[progress] want %v, got %v", tc.progress, gotProgress)
+			}
+			if !reflect.DeepEqual(gotTotals, tc.totals) {
+				t.Errorf("[totals] want %v, got %v", tc.totals, gotTotals)
+			}
+			if challenge.Status() != tc.status {
+				t.Errorf("[status] want %s, got %s", tc.status, challenge.Status())
+			}
+			if challenge.Remaining() != tc.remaining {
+				t.Errorf("[remaining] want %d, got %d", tc.remaining, challenge.Remaining())
+			}
+
+			_, err := challenge.roll(seeder)
+			if err != ErrChallengeFinished {
+				t.Errorf("[err] want %s, got %s", ErrChallengeFinished, err)
+			}
+		})
+	}
+}
+
+func TestExtendedChallenge_persisted(t *testing.T) {
+	challenge, err := NewExtendedChallenge("4d1", 10, 5)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	challenge.DicePenalty = 1
+
+	if _, err := challenge.Roll(); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	saved, err := json.Marshal(challenge)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	var loaded ExtendedChallenge
+	if err := json.Unmarshal(saved, &loaded); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if !reflect.DeepEqual(&loaded, challenge) {
+		t.Fatalf("want %+v, got %+v", challenge, loaded)
+	}
+
+	//the penalty carries over, 4 then 3 then 2 more reaches 10 on the fourth roll
+	for _, want := range []int{3, 2, 1} {
+		attempt, err := loaded.Roll()
+		if err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if attempt.Total != want {
+			t.Errorf("want %d, got %d", want, attempt.Total)
+		}
+	}
+	if loaded.Progress != 10 || loaded.Status() != ExtendedSucceeded {
+		t.Errorf("want 10 and %s, got %d and %s", ExtendedSucceeded, loaded.Progress, loaded.Status())
+	}
+}
@@ -0,0 +1,190 @@
+package dice
+
+import (
+	"sync"
+	"time"
+)
+
+//defaultHistoryLimit is the number of rolls remembered for each dice unless changed with SetHistoryLimit.
+const defaultHistoryLimit = 20
+
+//RollRecord is a single roll of a dice in a set.
+type RollRecord struct {
+	Time  time.Time `json:"time"`
+	Rolls []int     `json:"rolls"`
+	Sum   int       `json:"sum"`
+}
+
+//rollHistory holds the remembered rolls of one dice. Every dice has its own lock, so rolling different dice never waits on each other.
+type rollHistory struct {
+	m       sync.Mutex
+	records []RollRecord
+}
+
+//SetHistoryLimit sets how many rolls are remembered for each dice, older rolls are forgotten first.
+//A limit of zero or less turns history off and forgets every roll.
+func (s *Set) SetHistoryLimit(limit int) {
+	if limit <= 0 {
+		limit = -1
+	}
+	s.historyLimit.Store(int64(limit))
+
+	s.history.Range(func(name, value any) bool {
+		history := value.(*rollHistory)
+		history.m.Lock()
+		history.trim(s.limit())
+		if len(history.records) == 0 {
+			s.history.Delete(name)
+		}
+		history.m.Unlock()
+		return true
+	})
+}
+
+//HistoryLimit returns how many rolls are remembered for each dice, zero if history is turned off.
+func (s *Set) HistoryLimit() int {
+	return s.limit()
+}
+
+//History returns the remembered rolls of the named dice, oldest first.
+func (s *Set) History(name string) []RollRecord {
+	value, exists := s.history.Load(s.load().canonical(name))
+	if !exists {
+		return nil
+	}
+
+	history := value.(*rollHistory)
+	history.m.Lock()
+	defer history.m.Unlock()
+
+	return copyRecords(history.records)
+}
+
+//LastRoll returns the most recent roll of the named dice.
+//An error is returned if the dice has no remembered rolls.
+func (s *Set) LastRoll(name string) (RollRecord, error) {
+	records := s.History(name)
+	if len(records) == 0 {
+		return RollRecord{}, ErrNoRolls
+	}
+
+	return records[len(records)-1], nil
+}
+
+//AverageRoll returns the average sum of the remembered rolls of the named dice.
+//An error is returned if the dice has no remembered rolls.
+func (s *Set) AverageRoll(name string) (float64, error) {
+	records := s.History(name)
+	if len(records) == 0 {
+		return 0, ErrNoRolls
+	}
+
+	total := 0
+	for _, record := range records {
+		total += record.Sum
+	}
+
+	return float64(total) / float64(len(records)), nil
+}
+
+//FaceCounts returns how many times each face was rolled across the remembered rolls of the named dice.
+func (s *Set) FaceCounts(name string) map[int]int {
+	counts := make(map[int]int)
+	for _, record := range s.History(name) {
+		for _, roll := range record.Rolls {
+			counts[roll]++
+		}
+	}
+
+	return counts
+}
+
+//ClearHistory forgets every remembered roll of the named dice.
+func (s *Set) ClearHistory(name string) {
+	s.history.Delete(name)
+}
+
+//record remembers a roll of the named dice and returns the record of it. Only the history of the named dice is locked.
+func (s *Set) record(name string, rolls []int, sum int) RollRecord {
+	record := RollRecord{Time: time.Now(), Rolls: append([]int(nil), rolls...), Sum: sum}
+	limit := s.limit()
+	if limit == 0 {
+		return record
+	}
+
+	value, exists := s.history.Load(name)
+	if !exists {
+		value, _ = s.history.LoadOrStore(name, &rollHistory{})
+	}
+	history := value.(*rollHistory)
+
+	history.m.Lock()
+	defer history.m.Unlock()
+	history.records = append(history.records, record)
+	history.trim(limit)
+
+	return record
+}
+
+//histories returns a copy of the remembered rolls of every dice that has any.
+func (s *Set) histories() map[string][]RollRecord {
+	histories := make(map[string][]RollRecord)
+	s.history.Range(func(name, value any) bool {
+		history := value.(*rollHistory)
+		history.m.Lock()
+		if len(history.records) > 0 {
+			histories[name.(string)] = copyRecords(history.records)
+		}
+		history.m.Unlock()
+		return true
+	})
+
+	return histories
+}
+
+//replaceHistories forgets every remembered roll, remembering the provided rolls instead up to the limit.
+func (s *Set) replaceHistories(histories map[string][]RollRecord) {
+	s.history.Range(func(name, _ any) bool {
+		s.history.Delete(name)
+		return true
+	})
+
+	for name, records := range histories {
+		history := &rollHistory{records: copyRecords(records)}
+		history.trim(s.limit())
+		if len(history.records) > 0 {
+			s.history.Store(name, history)
+		}
+	}
+}
+
+//limit returns the effective history limit.
+func (s *Set) limit() int {
+	limit := s.historyLimit.Load()
+	switch {
+	case limit < 0:
+		return 0
+	case limit == 0:
+		return defaultHistoryLimit
+	}
+
+	return int(limit)
+}
+
+//trim forgets the oldest rolls beyond the limit. The caller must hold the history's lock.
+func (h *rollHistory) trim(limit int) {
+	if extra := len(h.records) - limit; extra > 0 {
+		h.records = append([]RollRecord(nil), h.records[extra:]...)
+	}
+}
+
+//copyRecords returns a copy of the records that shares nothing with them.
+func copyRecords(records []RollRecord) []RollRecord {
+	var copied []RollRecord
+	for _, record := range records {
+		record.Rolls = append([]int(nil), record.Rolls...)
+		copied = append(copied, record)
+	}
+
+	return copied
+}
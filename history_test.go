@@ -0,0 +1,152 @@
+package dice
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSet_History(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		subject := NewSet(map[string]string{"initiative": "1d1+2", "damage": "2d1"})
+		for i := 0; i < 3; i++ {
+			_, _, _ = subject.RollDice("initiative")
+		}
+		_, _, _ = subject.RollDice("damage")
+
+		got := subject.History("initiative")
+		if len(got) != 3 {
+			t.Fatalf("[len] want 3, got %d", len(got))
+		}
+		for _, record := range got {
+			if record.Sum != 3 || !reflect.DeepEqual(record.Rolls, []int{1}) || record.Time.IsZero() {
+				t.Errorf("unexpected record %v", record)
+			}
+		}
+
+		last, err := subject.LastRoll("damage")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if last.Sum != 2 {
+			t.Errorf("[last] want 2, got %d", last.Sum)
+		}
+
+		average, err := subject.AverageRoll("initiative")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if average != 3 {
+			t.Errorf("[average] want 3, got %f", average)
+		}
+
+		if got := subject.FaceCounts("damage"); !reflect.DeepEqual(got, map[int]int{1: 2}) {
+			t.Errorf("[faces] want %v, got %v", map[int]int{1: 2}, got)
+		}
+	})
+
+	t.Run("history is bounded", func(t *testing.T) {
+		subject := NewSet(map[string]string{"d6": "1d6"})
+		if subject.HistoryLimit() != defaultHistoryLimit {
+			t.Errorf("[default] want %d, got %d", defaultHistoryLimit, subject.HistoryLimit())
+		}
+
+		subject.SetHistoryLimit(5)
+		var sums []int
+		for i := 0; i < 8; i++ {
+			_, sum, _ := subject.RollDice("d6")
+			sums = append(sums, sum)
+		}
+
+		got := subject.History("d6")
+		if len(got) != 5 {
+			t.Fatalf("[len] want 5, got %d", len(got))
+		}
+		for i, record := range got {
+			if record.Sum != sums[i+3] {
+				t.Errorf("[order] want %d, got %d", sums[i+3], record.Sum)
+			}
+		}
+
+		subject.SetHistoryLimit(2)
+		if len(subject.History("d6")) != 2 {
+			t.Errorf("[trim] want 2, got %d", len(subject.History("d6")))
+		}
+
+		subject.SetHistoryLimit(0)
+		_, _, _ = subject.RollDice("d6")
+		if len(subject.History("d6")) != 0 || subject.HistoryLimit() != 0 {
+			t.Errorf("[off] want no history, got %v", subject.History("d6"))
+		}
+	})
+
+	t.Run("history is forgotten when dice are removed", func(t *testing.T) {
+		subject := NewSet(map[string]string{"d6": "1d6"})
+		_, _, _ = subject.RollDice("d6")
+		_ = subject.RemoveDice("d6")
+
+		if _, err := subject.LastRoll("d6"); err != ErrNoRolls {
+			t.Errorf("want %s, got %s", ErrNoRolls, err)
+		}
+		if _, err := subject.AverageRoll("d6"); err != ErrNoRolls {
+			t.Errorf("want %s, got %s", ErrNoRolls, err)
+		}
+	})
+
+	t.Run("history is saved with the set", func(t *testing.T) {
+		original := NewSet(map[string]string{"d6": "1d6"})
+		original.SetHistoryLimit(3)
+		for i := 0; i < 4; i++ {
+			_, _, _ = original.RollDice("d6")
+		}
+
+		var buf bytes.Buffer
+		if err := original.Save(&buf); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		loaded := &Set{}
+		if err := loaded.Load(&buf); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if loaded.HistoryLimit() != 3 {
+			t.Errorf("[limit] want 3, got %d", loaded.HistoryLimit())
+		}
+		want, got := original.History("d6"), loaded.History("d6")
+		if len(got) != len(want) {
+			t.Fatalf("[len] want %d, got %d", len(want), len(got))
+		}
+		for i := range want {
+			if !got[i].Time.Equal(want[i].Time) || got[i].Sum != want[i].Sum || !reflect.DeepEqual(got[i].Rolls, want[i].Rolls) {
+				t.Errorf("want %v, got %v", want[i], got[i])
+			}
+		}
+	})
+}
+
+func Test_race_history(t *testing.T) {
+	subject := NewSet(map[string]string{"d6": "1d6"})
+	subject.SetHistoryLimit(10)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_, _, _ = subject.RollDice("d6")
+				_, _ = subject.AverageRoll("d6")
+				subject.FaceCounts("d6")
+				if i%10 == 0 {
+					subject.SetHistoryLimit(5 + g)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if got := len(subject.History("d6")); got > 8 {
+		t.Errorf("want at most 8 records, got %d", got)
+	}
+}
@@ -0,0 +1,87 @@
+package dice
+
+import "sync"
+
+//layering serializes changes to parents so two sets can never become each other's parent.
+var layering sync.Mutex
+
+//SetParent stacks the set on top of parent. Dice that are not in the set are looked up in parent,
+//and then its parents, letting a set override or add to the dice it inherits. Pass nil to remove the parent.
+//
+//Lookups read each layer's current snapshot from the child up without locking, so a parent can safely be shared by many sets.
+//An error is returned if the set is already a parent of parent.
+func (s *Set) SetParent(parent *Set) error {
+	layering.Lock()
+	defer layering.Unlock()
+
+	for layer := parent; layer != nil; layer = layer.Parent() {
+		if layer == s {
+			return ErrCyclicLayers
+		}
+	}
+
+	return s.update(func(next *setState) ([]Event, error) {
+		next.parent = parent
+		return nil, nil
+	})
+}
+
+//Parent returns the set this set inherits dice from, or nil if it has none.
+func (s *Set) Parent() *Set {
+	return s.load().parent
+}
+
+//DefinedIn returns the layer that defines the named dice, either the set itself or one of its parents.
+func (s *Set) DefinedIn(name string) (*Set, error) {
+	for layer := s; layer != nil; {
+		state := layer.load()
+		name = state.canonical(name)
+		if _, exists := state.dice[name]; exists {
+			return layer, nil
+		}
+		layer = state.parent
+	}
+
+	return nil, ErrDiceNotFound
+}
+
+//lookup returns the entry for name, checking this state first and then the state of each parent.
+//Aliases and case are resolved by each layer using its own options.
+func (st *setState) lookup(name string) (Entry, bool) {
+	for state := st; ; {
+		name = state.canonical(name)
+		if entry, exists := state.dice[name]; exists {
+			return entry, true
+		}
+		if state = state.parentState(); state == nil {
+			return Entry{}, false
+		}
+	}
+}
+
+//effective returns every entry visible from this state, entries in a set override those in its parents.
+func (st *setState) effective() map[string]Entry {
+	entries := make(map[string]Entry, len(st.dice))
+	for state := st; ; {
+		for name, entry := range state.dice {
+			if _, exists := entries[name]; !exists {
+				entries[name] = entry
+			}
+		}
+		if state = state.parentState(); state == nil {
+			return entries
+		}
+	}
+}
+
+//empty returns true if neither this state nor any of its parents have dice.
+func (st *setState) empty() bool {
+	for state := st; ; {
+		if len(state.dice) > 0 {
+			return false
+		}
+		if state = state.parentState(); state == nil {
+			return true
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package dice
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSet_SetParent(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		global := NewSet(map[string]string{"initiative": "1d20", "torch": "1d4"})
+		party := NewSet(map[string]string{"initiative": "1d20+2", "bless": "1d4"})
+		character := NewSet(map[string]string{"rapier": "1d8+4"})
+
+		if err := party.SetParent(global); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if err := character.SetParent(party); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if character.Parent() != party || party.Parent() != global || global.Parent() != nil {
+			t.Error("[parent] unexpected parents")
+		}
+
+		want := []string{"bless,1d4", "initiative,1d20+2", "rapier,1d8+4", "torch,1d4"}
+		if got := character.ListDice(); !reflect.DeepEqual(got, want) {
+			t.Errorf("[list] want %s, got %s", want, got)
+		}
+
+		for name, want := range map[string]*Set{"rapier": character, "initiative": party, "bless": party, "torch": global} {
+			got, err := character.DefinedIn(name)
+			if err != nil {
+				t.Errorf("unexpected error, %s", err)
+			}
+			if got != want {
+				t.Errorf("[defined in] %s defined in the wrong layer", name)
+			}
+		}
+
+		if _, err := character.DefinedIn("fireball"); err != ErrDiceNotFound {
+			t.Errorf("want %s, got %s", ErrDiceNotFound, err)
+		}
+
+		rolls, _, err := character.RollDice("torch")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if len(rolls) != 1 || rolls[0] < 1 || rolls[0] > 4 {
+			t.Errorf("[rolls] want one roll 1-4, got %v", rolls)
+		}
+
+		if got, _ := character.Stats("initiative"); got.Mean != 12.5 {
+			t.Errorf("[stats] want mean 12.5, got %f", got.Mean)
+		}
+	})
+
+	t.Run("references resolve from the rolling layer", func(t *testing.T) {
+		parent := NewSet(map[string]string{"weapon": "1d1", "attack": "@weapon+1"})
+		child := NewSet(map[string]string{"weapon": "3d1"})
+		if err := child.SetParent(parent); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if _, sum, _ := parent.RollDice("attack"); sum != 2 {
+			t.Errorf("[parent] want 2, got %d", sum)
+		}
+		if _, sum, _ := child.RollDice("attack"); sum != 4 {
+			t.Errorf("[child] want 4, got %d", sum)
+		}
+
+		if err := child.AddDice("weapon", "@attack"); err != ErrCyclicReference {
+			t.Errorf("want %s, got %s", ErrCyclicReference, err)
+		}
+	})
+
+	t.Run("removing only affects the layer", func(t *testing.T) {
+		parent := NewSet(map[string]string{"torch": "1d4"})
+		child := &Set{}
+		if err := child.SetParent(parent); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if _, _, err := child.RollDice("torch"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if err := child.RemoveDice("torch"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if _, _, err := child.RollDice("torch"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if got := len(child.History("torch")); got != 2 {
+			t.Errorf("[history] want 2 rolls kept, got %d", got)
+		}
+
+		if err := child.SetParent(nil); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if _, _, err := child.RollDice("torch"); err != ErrEmptyDiceSet {
+			t.Errorf("want %s, got %s", ErrEmptyDiceSet, err)
+		}
+	})
+
+	t.Run("error when layers form a cycle", func(t *testing.T) {
+		a, b, c := &Set{}, &Set{}, &Set{}
+		_ = b.SetParent(a)
+		_ = c.SetParent(b)
+
+		if err := a.SetParent(c); err != ErrCyclicLayers {
+			t.Errorf("want %s, got %s", ErrCyclicLayers, err)
+		}
+		if err := a.SetParent(a); err != ErrCyclicLayers {
+			t.Errorf("want %s, got %s", ErrCyclicLayers, err)
+		}
+	})
+}
+
+func Test_race_layers(t *testing.T) {
+	shared := NewSet(map[string]string{"base": "1d6"})
+	children := []*Set{{}, {}, {}}
+	for _, child := range children {
+		_ = child.SetParent(shared)
+	}
+
+	var wg sync.WaitGroup
+	for c, child := range children {
+		wg.Add(2)
+		go func(c int, child *Set) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_ = child.AddDice(fmt.Sprintf("%d-%d", c, i), "@base+1")
+				_, _, _ = child.RollDice(fmt.Sprintf("%d-%d", c, i))
+				child.ListDice()
+			}
+		}(c, child)
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_ = shared.AddDice(fmt.Sprintf("shared-%d-%d", c, i), "1d4")
+				_ = shared.RemoveDice(fmt.Sprintf("shared-%d-%d", c, i))
+			}
+		}(c)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,69 @@
+package dice
+
+import "time"
+
+//NaturalRules controls how the natural roll of a challenge, its first dice before modifiers, decides the outcome
+//regardless of the total.
+type NaturalRules struct {
+	CritRange   int  //natural rolls at or above this always succeed and threaten a critical, 0 disables, e.g. 19
+	FumbleRange int  //natural rolls at or below this always fail as a fumble, 0 disables, e.g. 1
+	Confirm     bool //a threat is only a critical if a second roll also succeeds, 3.5e and Pathfinder 1e style
+}
+
+//NaturalChallengeResult holds a challenge roll judged with natural rules.
+type NaturalChallengeResult struct {
+	Success           bool
+	Critical          bool //the natural roll threatened a critical and it was confirmed if needed
+	Threat            bool //the natural roll was in the critical range
+	Fumble            bool //the natural roll was in the fumble range
+	Rolls             []int
+	Natural           int
+	Total             int
+	Confirmation      []int //rolls of the confirmation roll, if one was needed
+	ConfirmationTotal int
+}
+
+//RollNaturalChallenge rolls an expression against a provided value like RollChallenge, except that a natural roll
+//in the critical range always succeeds and one in the fumble range always fails. When the rules require confirmation
+//the expression is rolled again on a threat and only becomes a critical if that roll also beats the value.
+//
+//An error is returned if the expression is not a valid roll expression.
+func RollNaturalChallenge(expression string, against int, equalSucceeds bool, rules NaturalRules) (NaturalChallengeResult, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return NaturalChallengeResult{}, err
+	}
+
+	result := rollChallenge(expression, parsed, against, ChallengeOptions{EqualSucceeds: equalSucceeds, Natural: rules}, New(time.Now().UnixNano()))
+
+	return NaturalChallengeResult{
+		Success:           result.Success,
+		Critical:          result.Critical,
+		Threat:            result.Threat,
+		Fumble:            result.Fumble,
+		Rolls:             result.Rolls,
+		Natural:           result.Natural,
+		Total:             result.Total,
+		Confirmation:      result.Confirmation,
+		ConfirmationTotal: result.ConfirmationTotal,
+	}, nil
+}
+
+//judge applies the rules to a natural roll that succeeded or not on its total, calling confirm for a confirmation roll when one is needed.
+func (r NaturalRules) judge(natural int, succeeded bool, confirm func() bool) (success bool, critical bool, threat bool, fumble bool) {
+	switch {
+	case r.CritRange > 0 && natural >= r.CritRange:
+		threat = true
+		critical = !r.Confirm || confirm()
+		return true, critical, threat, false
+	case r.FumbleRange > 0 && natural <= r.FumbleRange:
+		return false, false, false, true
+	}
+
+	return succeeded, false, false, false
+}
+
+//beats returns true if total succeeds against the value, see RollChallenge.
+func beats(total int, against int, equalSucceeds bool) bool {
+	return total > against || equalSucceeds && total == against
+}
@@ -0,0 +1,86 @@
+package dice
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRollNaturalChallenge(t *testing.T) {
+	testCases := []struct {
+		expression    string
+		against       int
+		equalSucceeds bool
+		rules         NaturalRules
+		want          NaturalChallengeResult
+		err           error
+	}{
+		{
+			expression: "1d1+9",
+			against:    9,
+			want:       NaturalChallengeResult{Success: true},
+		},
+		{
+			expression:    "1d1+9",
+			against:       10,
+			equalSucceeds: true,
+			want:          NaturalChallengeResult{Success: true},
+		},
+		{
+			expression: "1d1",
+			against:    15,
+			rules:      NaturalRules{CritRange: 1},
+			want:       NaturalChallengeResult{Success: true, Critical: true, Threat: true},
+		},
+		{
+			expression: "1d1+20",
+			against:    15,
+			rules:      NaturalRules{FumbleRange: 1},
+			want:       NaturalChallengeResult{Fumble: true},
+		},
+		{
+			expression: "1d1+20",
+			against:    15,
+			rules:      NaturalRules{CritRange: 1, Confirm: true},
+			want:       NaturalChallengeResult{Success: true, Critical: true, Threat: true},
+		},
+		{
+			expression: "1d1",
+			against:    15,
+			rules:      NaturalRules{CritRange: 1, Confirm: true},
+			want:       NaturalChallengeResult{Success: true, Threat: true},
+		},
+		{
+			expression: "1d1+2d1",
+			against:    15,
+			rules:      NaturalRules{CritRange: 2},
+			want:       NaturalChallengeResult{},
+		},
+		{
+			expression: "1d",
+			against:    15,
+			err:        ErrInvalidRollExpression,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s against %d", i, tc.expression, tc.against), func(t *testing.T) {
+			got, err := RollNaturalChallenge(tc.expression, tc.against, tc.equalSucceeds, tc.rules)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Success != tc.want.Success || got.Critical != tc.want.Critical || got.Threat != tc.want.Threat || got.Fumble != tc.want.Fumble {
+				t.Errorf("want %+v, got %+v", tc.want, got)
+			}
+			if got.Natural != 1 {
+				t.Errorf("[natural] want 1, got %d", got.Natural)
+			}
+			if confirmed := len(got.Confirmation) > 0; confirmed != (tc.rules.Confirm && got.Threat) || confirmed && got.ConfirmationTotal != got.Total {
+				t.Errorf("[confirmation] unexpected confirmation roll %v totaling %d", got.Confirmation, got.ConfirmationTotal)
+			}
+		})
+	}
+}
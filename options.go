@@ -0,0 +1,149 @@
+package dice
+
+import (
+	"sort"
+	"strings"
+)
+
+//SetOption configures a set created with NewSet.
+type SetOption func(*Set)
+
+//CaseInsensitive makes the set ignore case when looking up dice and aliases, so "Fireball" and "fireball" name the same dice.
+//Adding a dice whose name only differs in case from an existing dice replaces it and keeps the existing name.
+func CaseInsensitive() SetOption {
+	return func(s *Set) {
+		_ = s.update(func(next *setState) ([]Event, error) {
+			next.caseInsensitive = true
+			return nil, nil
+		})
+	}
+}
+
+//WithAliases adds alternate names for dice in the set, each alias maps to the name of a dice. The dice can reference
+//each other by alias. Once every dice has been added the aliases are checked the same way as AddAlias, any that it
+//would reject are dropped.
+func WithAliases(aliases map[string]string) SetOption {
+	return func(s *Set) {
+		_ = s.update(func(next *setState) ([]Event, error) {
+			for alias, name := range aliases {
+				next.aliases[alias] = name
+			}
+			return nil, nil
+		})
+	}
+}
+
+//AddAlias adds an alternate name for a dice in the set or its parents. The alias can be used anywhere the name can,
+//including references from other dice. An error is returned if the dice does not exist or the alias is already a dice in the set.
+func (s *Set) AddAlias(alias string, name string) error {
+	return s.update(func(next *setState) ([]Event, error) {
+		target, err := next.checkAlias(alias, name)
+		if err != nil {
+			return nil, err
+		}
+		next.aliases[alias] = target
+
+		return nil, nil
+	})
+}
+
+//checkAliases drops every alias that AddAlias would reject, pointing the rest at the name their dice is stored under.
+//Only a state that has not been stored yet can be changed. The dropped aliases are returned in order along with why.
+func (st *setState) checkAliases() []RejectedDice {
+	aliases := make([]string, 0, len(st.aliases))
+	for alias := range st.aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var dropped []RejectedDice
+	for _, alias := range aliases {
+		target, err := st.checkAlias(alias, st.aliases[alias])
+		if err != nil {
+			dropped = append(dropped, RejectedDice{Name: alias, AliasOf: st.aliases[alias], Err: err})
+			delete(st.aliases, alias)
+			continue
+		}
+		st.aliases[alias] = target
+	}
+
+	return dropped
+}
+
+//checkAlias makes sure alias can name the dice, returning the name the dice is stored under.
+func (st *setState) checkAlias(alias string, name string) (string, error) {
+	for other := range st.dice {
+		if other == alias || st.caseInsensitive && strings.EqualFold(other, alias) {
+			return "", ErrDuplicateDice
+		}
+	}
+
+	entry, exists := st.lookup(name)
+	if !exists {
+		return "", ErrDiceNotFound
+	}
+
+	return entry.Name, nil
+}
+
+//RemoveAlias removes an alternate name from the set, the dice it named is not changed.
+func (s *Set) RemoveAlias(alias string) {
+	_ = s.update(func(next *setState) ([]Event, error) {
+		delete(next.aliases, alias)
+		return nil, nil
+	})
+}
+
+//Aliases returns every alias in the set mapped to the name of its dice.
+func (s *Set) Aliases() map[string]string {
+	state := s.load()
+	aliases := make(map[string]string, len(state.aliases))
+	for alias, name := range state.aliases {
+		aliases[alias] = name
+	}
+
+	return aliases
+}
+
+//canonical returns the name the state stores the dice under, resolving aliases and, if the set is case insensitive,
+//differences in case. Names that are not found are returned unchanged.
+func (st *setState) canonical(name string) string {
+	if _, exists := st.dice[name]; exists {
+		return name
+	}
+	if target, exists := st.aliases[name]; exists {
+		return target
+	}
+
+	if st.caseInsensitive {
+		//pick the lowest matching name so the same dice always wins if several only differ in case
+		found, key := false, ""
+		for other := range st.dice {
+			if strings.EqualFold(other, name) && (!found || other < key) {
+				found, key = true, other
+			}
+		}
+		if found {
+			return key
+		}
+		for alias := range st.aliases {
+			if strings.EqualFold(alias, name) && (!found || alias < key) {
+				found, key = true, alias
+			}
+		}
+		if found {
+			return st.aliases[key]
+		}
+	}
+
+	return name
+}
+
+//removeAliases forgets every alias of the named dice. Only a state that has not been stored yet can be changed.
+func (st *setState) removeAliases(name string) {
+	for alias, target := range st.aliases {
+		if target == name {
+			delete(st.aliases, alias)
+		}
+	}
+}
@@ -81,6 +81,17 @@ func (s *seeder) RandomNRange(count, min, max int, unique bool) []int {
 	return results
 }
 
+//rollDice rolls the specified number of n-sided dice and returns the rolled results and their sum.
+func (s *seeder) rollDice(number int, sides int) ([]int, int) {
+	rolls := s.RandomNRange(number, 1, sides, false)
+	sum := 0
+	for _, roll := range rolls {
+		sum += roll
+	}
+
+	return rolls, sum
+}
+
 func New(seed int64) *seeder {
 	s := &seeder{seed: seed}
 	s.Reset()
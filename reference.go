@@ -0,0 +1,352 @@
+package dice
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var referenceRE = regexp.MustCompile(`^@(?:\{([^{}]+)\}|([A-Za-z0-9_.]+))$`) //a reference to another dice in a set (e.g. "@rapier" or "@{main weapon}")
+
+//setTerm is one part of a set expression, either a roll expression, a constant, or a reference to another dice.
+type setTerm struct {
+	subtract   bool
+	expression string
+	constant   int
+	reference  string
+}
+
+//DependencyError is returned when removing a dice that other dice in the set reference.
+type DependencyError struct {
+	Name       string
+	Dependents []string
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("dice %q is used by %s", e.Name, strings.Join(e.Dependents, ", "))
+}
+
+//Is allows errors.Is to match a DependencyError with ErrDiceInUse.
+func (e *DependencyError) Is(target error) bool {
+	return target == ErrDiceInUse
+}
+
+//ReferenceError is returned when a dice references a dice that does not exist.
+type ReferenceError struct {
+	Name      string
+	Reference string
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("dice %q references %q which does not exist", e.Name, e.Reference)
+}
+
+//Is allows errors.Is to match a ReferenceError with ErrMissingReference.
+func (e *ReferenceError) Is(target error) bool {
+	return target == ErrMissingReference
+}
+
+//parseSetExpression breaks a set expression into its terms. A set expression is either a roll expression,
+//or roll expressions, constants, and references to other dice joined with + or - (e.g. "@rapier+@sneak+2").
+//Names made of letters, digits, underscores, and dots can be referenced as @name, any other name as @{name}.
+func parseSetExpression(expression string) ([]setTerm, error) {
+	if ValidRollExpression(expression) {
+		return []setTerm{{expression: expression}}, nil
+	}
+
+	if !strings.Contains(expression, "@") {
+		return nil, ErrInvalidRollExpression
+	}
+
+	//split on every + and - outside of braces, ops[i] is the operator before atoms[i]
+	atoms, ops := []string{""}, []byte{'+'}
+	inBraces := false
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+		switch {
+		case c == '{':
+			inBraces = true
+		case c == '}':
+			inBraces = false
+		case (c == '+' || c == '-') && !inBraces:
+			atoms, ops = append(atoms, ""), append(ops, c)
+			continue
+		}
+		atoms[len(atoms)-1] += string(c)
+	}
+
+	var terms []setTerm
+	for i := 0; i < len(atoms); {
+		subtract := ops[i] == '-'
+
+		if strings.HasPrefix(atoms[i], "@") {
+			match := referenceRE.FindStringSubmatch(atoms[i])
+			if match == nil {
+				return nil, ErrInvalidRollExpression
+			}
+			terms = append(terms, setTerm{subtract: subtract, reference: match[1] + match[2]})
+			i++
+			continue
+		}
+
+		//find the longest run of atoms that forms a roll expression, falling back to a constant. A subtracted term
+		//is a single atom, grouping would subtract the modifiers that follow it too (e.g. "@a-1d4+1" is not "@a-(1d4+1)")
+		last := len(atoms) - 1
+		if subtract {
+			last = i
+		}
+		found := false
+		for j := last; j >= i && !found; j-- {
+			text := atoms[i]
+			for k := i + 1; k <= j; k++ {
+				text += string(ops[k]) + atoms[k]
+			}
+			if strings.Contains(text, "@") {
+				continue
+			}
+
+			if ValidRollExpression(text) {
+				terms = append(terms, setTerm{subtract: subtract, expression: text})
+				i, found = j+1, true
+			} else if constant, err := strconv.Atoi(text); j == i && err == nil && constant >= 0 {
+				terms = append(terms, setTerm{subtract: subtract, constant: constant})
+				i, found = j+1, true
+			}
+		}
+		if !found {
+			return nil, ErrInvalidRollExpression
+		}
+	}
+
+	return terms, nil
+}
+
+//references returns the names of every dice referenced by the expression.
+func references(expression string) []string {
+	terms, _ := parseSetExpression(expression)
+
+	var names []string
+	for _, term := range terms {
+		if term.reference != "" {
+			names = append(names, term.reference)
+		}
+	}
+
+	return names
+}
+
+//renameReferences returns the expression with every reference to name changed to newName.
+func (st *setState) renameReferences(expression string, name string, newName string) string {
+	terms, err := parseSetExpression(expression)
+	if err != nil || len(terms) == 1 && terms[0].reference == "" {
+		return expression
+	}
+
+	var b strings.Builder
+	for i, term := range terms {
+		if term.subtract {
+			b.WriteString("-")
+		} else if i > 0 {
+			b.WriteString("+")
+		}
+
+		switch {
+		case term.reference != "":
+			reference := term.reference
+			if st.canonical(reference) == name {
+				reference = newName
+			}
+			if match := referenceRE.FindStringSubmatch("@" + reference); match != nil && match[2] != "" {
+				b.WriteString("@" + reference)
+			} else {
+				b.WriteString("@{" + reference + "}")
+			}
+		case term.expression != "":
+			b.WriteString(term.expression)
+		default:
+			b.WriteString(strconv.Itoa(term.constant))
+		}
+	}
+
+	return b.String()
+}
+
+//reaches returns true if following references from the named dice leads to target.
+func (st *setState) reaches(name string, target string, visited map[string]bool) bool {
+	if visited[name] {
+		return false
+	}
+	visited[name] = true
+
+	entry, _ := st.lookup(name)
+	for _, reference := range references(entry.Expression) {
+		if st.canonical(reference) == target || st.reaches(reference, target, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//dependents returns the names of every dice in this state that references the named dice, sorted by name.
+func (st *setState) dependents(name string) []string {
+	var names []string
+	for other, entry := range st.dice {
+		for _, reference := range references(entry.Expression) {
+			if st.canonical(reference) == name {
+				names = append(names, other)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+//checkReferences makes sure storing expression under name would not create a cycle of references.
+func (st *setState) checkReferences(name string, expression string) error {
+	for _, reference := range references(expression) {
+		if st.canonical(reference) == name || st.reaches(reference, name, make(map[string]bool)) {
+			return ErrCyclicReference
+		}
+	}
+
+	return nil
+}
+
+//checkResolved makes sure every dice referenced by expression, other than name itself, can be looked up.
+//A *ReferenceError naming the first missing dice is returned if not.
+func (st *setState) checkResolved(name string, expression string) error {
+	for _, reference := range references(expression) {
+		if _, exists := st.lookup(reference); !exists && st.canonical(reference) != name {
+			return &ReferenceError{Name: name, Reference: reference}
+		}
+	}
+
+	return nil
+}
+
+//referencesFirst returns the entries ordered so each comes after the entries it references, otherwise keeping
+//their order. References are matched to entries by the name resolve returns for them, references that are missing
+//or part of a cycle are left for adding to report.
+func referencesFirst(entries []Entry, resolve func(name string) string) []Entry {
+	byName := make(map[string]int, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		byName[entries[i].Name] = i
+	}
+
+	ordered := make([]Entry, 0, len(entries))
+	placed := make([]bool, len(entries))
+	var place func(i int)
+	place = func(i int) {
+		if placed[i] {
+			return
+		}
+		placed[i] = true
+		for _, reference := range references(entries[i].Expression) {
+			if j, exists := byName[resolve(reference)]; exists {
+				place(j)
+			}
+		}
+		ordered = append(ordered, entries[i])
+	}
+	for i := range entries {
+		place(i)
+	}
+
+	return ordered
+}
+
+//roll rolls the named dice, rolling any dice it references along the way. References are looked up from
+//this state, so a set can override the dice referenced by an inherited dice.
+func (st *setState) roll(name string, seeder *seeder, rolling map[string]bool) ([]int, int, error) {
+	entry, exists := st.lookup(name)
+	if !exists {
+		return nil, 0, ErrDiceNotFound
+	}
+	if rolling[entry.Name] {
+		return nil, 0, ErrCyclicReference
+	}
+	rolling[entry.Name] = true
+	defer delete(rolling, entry.Name)
+
+	terms, err := parseSetExpression(entry.Expression)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rolls []int
+	var sum int
+	for _, term := range terms {
+		var termRolls []int
+		termSum := term.constant
+		switch {
+		case term.reference != "":
+			if _, exists := st.lookup(term.reference); !exists {
+				return nil, 0, &ReferenceError{Name: entry.Name, Reference: term.reference}
+			}
+			termRolls, termSum, err = st.roll(term.reference, seeder, rolling)
+			if err != nil {
+				return nil, 0, err
+			}
+		case term.expression != "":
+			parsed, _ := parseExpression(term.expression)
+			termRolls, _, termSum = parsed.roll(seeder)
+		}
+
+		rolls = append(rolls, termRolls...)
+		if term.subtract {
+			sum -= termSum
+		} else {
+			sum += termSum
+		}
+	}
+
+	return rolls, sum, nil
+}
+
+//distribution returns the distribution of the named dice, following any dice it references.
+func (st *setState) distribution(name string, visiting map[string]bool) (*Distribution, error) {
+	entry, exists := st.lookup(name)
+	if !exists {
+		return nil, ErrDiceNotFound
+	}
+	if visiting[entry.Name] {
+		return nil, ErrCyclicReference
+	}
+	visiting[entry.Name] = true
+	defer delete(visiting, entry.Name)
+
+	terms, err := parseSetExpression(entry.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	d := pointDistribution(0)
+	for _, term := range terms {
+		termDist := pointDistribution(term.constant)
+		switch {
+		case term.reference != "":
+			if _, exists := st.lookup(term.reference); !exists {
+				return nil, &ReferenceError{Name: entry.Name, Reference: term.reference}
+			}
+			termDist, err = st.distribution(term.reference, visiting)
+			if err != nil {
+				return nil, err
+			}
+		case term.expression != "":
+			parsed, _ := parseExpression(term.expression)
+			termDist = parsed.distribution()
+		}
+
+		if term.subtract {
+			termDist = termDist.transform(func(v int) int { return -v })
+		}
+		d = d.convolve(termDist)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,281 @@
+package dice
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_parseSetExpression(t *testing.T) {
+	testCases := []struct {
+		expression string
+		want       []setTerm
+		err        error
+	}{
+		{
+			expression: "2d20+3-2d6-1",
+			want:       []setTerm{{expression: "2d20+3-2d6-1"}},
+		},
+		{
+			expression: "@rapier+@sneak",
+			want:       []setTerm{{reference: "rapier"}, {reference: "sneak"}},
+		},
+		{
+			expression: "@{main weapon}-@{sneak-attack}+2",
+			want:       []setTerm{{reference: "main weapon"}, {subtract: true, reference: "sneak-attack"}, {constant: 2}},
+		},
+		{
+			expression: "2d6+3-@rapier+1d4+1",
+			want:       []setTerm{{expression: "2d6+3"}, {subtract: true, reference: "rapier"}, {expression: "1d4+1"}},
+		},
+		{
+			expression: "@rapier+2d20+3+2d6+1",
+			want:       []setTerm{{reference: "rapier"}, {expression: "2d20+3+2d6+1"}},
+		},
+		{
+			expression: "@rapier+1+1d6+2+3",
+			want:       []setTerm{{reference: "rapier"}, {constant: 1}, {expression: "1d6+2"}, {constant: 3}},
+		},
+		{
+			expression: "@a-1d4+1",
+			want:       []setTerm{{reference: "a"}, {subtract: true, expression: "1d4"}, {constant: 1}},
+		},
+		{
+			expression: "@a-2d6-1",
+			want:       []setTerm{{reference: "a"}, {subtract: true, expression: "2d6"}, {subtract: true, constant: 1}},
+		},
+		{
+			expression: "heyo",
+			err:        ErrInvalidRollExpression,
+		},
+		{
+			expression: "@rapier@sneak",
+			err:        ErrInvalidRollExpression,
+		},
+		{
+			expression: "@rapier++@sneak",
+			err:        ErrInvalidRollExpression,
+		},
+		{
+			expression: "half:2d6+@rapier",
+			err:        ErrInvalidRollExpression,
+		},
+		{
+			expression: "@rapier*2",
+			err:        ErrInvalidRollExpression,
+		},
+		{
+			expression: "@{}+1d6",
+			err:        ErrInvalidRollExpression,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s", i, tc.expression), func(t *testing.T) {
+			got, err := parseSetExpression(tc.expression)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+
+			if err != tc.err {
+				t.Errorf("[err] want %s, got %s", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSet_References(t *testing.T) {
+	t.Run("rolls referenced dice", func(t *testing.T) {
+		subject := NewSet(map[string]string{
+			"sneak":        "3d1",
+			"rapier":       "1d1+4",
+			"sneak-attack": "@rapier+@sneak",
+			"flurry":       "@{sneak-attack}-1+1d1",
+		})
+
+		rolls, sum, err := subject.RollDice("flurry")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		if !reflect.DeepEqual(rolls, []int{1, 1, 1, 1, 1}) {
+			t.Errorf("[rolls] want %v, got %v", []int{1, 1, 1, 1, 1}, rolls)
+		}
+		if sum != 8 {
+			t.Errorf("[sum] want %d, got %d", 8, sum)
+		}
+	})
+
+	t.Run("subtraction only applies to the next term", func(t *testing.T) {
+		subject := NewSet(map[string]string{"a": "1d1", "b": "@a-1d1+5"})
+
+		_, sum, err := subject.RollDice("b")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if sum != 5 {
+			t.Errorf("[sum] want 5, got %d", sum)
+		}
+
+		got, err := subject.Stats("b")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if got.Mean != 5 {
+			t.Errorf("[mean] want 5, got %v", got.Mean)
+		}
+
+		if err := subject.Rename("a", "c"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if entry, _ := subject.Get("b"); entry.Expression != "@c-1d1+5" {
+			t.Errorf("[rename] want @c-1d1+5, got %s", entry.Expression)
+		}
+	})
+
+	t.Run("stats include referenced dice", func(t *testing.T) {
+		subject := NewSet(map[string]string{"sneak": "3d6", "rapier": "1d8+4", "sneak-attack": "@rapier+@sneak"})
+
+		got, err := subject.Stats("sneak-attack")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		if got.Mean != 19 || got.Min != 8 || got.Max != 30 || !got.Exact {
+			t.Errorf("want mean 19 from 8 to 30, got %v", got)
+		}
+	})
+
+	t.Run("error when referenced dice does not exist", func(t *testing.T) {
+		subject := NewSet(map[string]string{"sneak": "3d6"})
+
+		err := subject.AddDice("sneak-attack", "@sneak+@rapier")
+		var referenceErr *ReferenceError
+		if !errors.As(err, &referenceErr) || !errors.Is(err, ErrMissingReference) {
+			t.Fatalf("want *ReferenceError, got %v", err)
+		}
+		if err.Error() != `dice "sneak-attack" references "rapier" which does not exist` {
+			t.Errorf("unexpected message, %s", err)
+		}
+		if _, err := subject.Get("sneak-attack"); err != ErrDiceNotFound {
+			t.Errorf("want %s, got %v", ErrDiceNotFound, err)
+		}
+	})
+
+	t.Run("error when rolling a reference that no longer exists", func(t *testing.T) {
+		parent := NewSet(map[string]string{"rapier": "1d8+4"})
+		subject := NewSet(nil)
+		if err := subject.SetParent(parent); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if err := subject.AddDice("sneak-attack", "@rapier+3d6"); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if err := parent.RemoveDice("rapier"); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		want := &ReferenceError{Name: "sneak-attack", Reference: "rapier"}
+		if _, _, err := subject.RollDice("sneak-attack"); !reflect.DeepEqual(err, want) {
+			t.Errorf("want %v, got %v", want, err)
+		}
+		if _, err := subject.Stats("sneak-attack"); !reflect.DeepEqual(err, want) {
+			t.Errorf("[stats] want %v, got %v", want, err)
+		}
+	})
+
+	t.Run("references can be given in any order", func(t *testing.T) {
+		subject := NewSet(map[string]string{"a": "@b+1", "b": "@c+1d4", "c": "1d6"})
+
+		if subject.Len() != 3 {
+			t.Errorf("want 3 dice, got %v", subject.ListDice())
+		}
+	})
+
+	t.Run("error when adding a cycle", func(t *testing.T) {
+		subject := NewSet(map[string]string{"a": "@b+1", "b": "@c+1d4", "c": "1d4"})
+
+		err := subject.AddDice("c", "@a")
+		if err != ErrCyclicReference {
+			t.Errorf("want %s, got %s", ErrCyclicReference, err)
+		}
+
+		err = subject.AddEntry(Entry{Name: "d", Expression: "@d+1"})
+		if err != ErrCyclicReference {
+			t.Errorf("want %s, got %s", ErrCyclicReference, err)
+		}
+
+		err = subject.AddDice("c", "1d6")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+	})
+
+	t.Run("error when removing a dice in use", func(t *testing.T) {
+		subject := NewSet(map[string]string{"sneak": "3d6", "rapier": "1d8+4", "sneak-attack": "@rapier+@sneak", "backstab": "@sneak+@sneak"})
+
+		err := subject.RemoveDice("sneak")
+		var dependencyErr *DependencyError
+		if !errors.As(err, &dependencyErr) || !errors.Is(err, ErrDiceInUse) {
+			t.Fatalf("want *DependencyError, got %v", err)
+		}
+		if !reflect.DeepEqual(dependencyErr.Dependents, []string{"backstab", "sneak-attack"}) {
+			t.Errorf("want %v, got %v", []string{"backstab", "sneak-attack"}, dependencyErr.Dependents)
+		}
+		if err.Error() != `dice "sneak" is used by backstab, sneak-attack` {
+			t.Errorf("unexpected message, %s", err)
+		}
+
+		if err := subject.RemoveDice("sneak-attack"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if err := subject.RemoveDice("backstab"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if err := subject.RemoveDice("sneak"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+	})
+
+	t.Run("load rejects cycles", func(t *testing.T) {
+		subject := &Set{}
+		err := subject.UnmarshalJSON([]byte(`{"version":1,"dice":[{"name":"a","expression":"@b"},{"name":"b","expression":"@a+1"},{"name":"c","expression":"@b+1d4"}]}`))
+
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("want *LoadError, got %v", err)
+		}
+		want := []RejectedDice{
+			{Name: "a", Expression: "@b", Err: ErrCyclicReference},
+			{Name: "b", Expression: "@a+1", Err: &ReferenceError{Name: "b", Reference: "a"}},
+			{Name: "c", Expression: "@b+1d4", Err: &ReferenceError{Name: "c", Reference: "b"}},
+		}
+		if !reflect.DeepEqual(loadErr.Rejected, want) {
+			t.Errorf("want %v, got %v", want, loadErr.Rejected)
+		}
+		if subject.ListDice() != nil {
+			t.Errorf("want no dice, got %v", subject.ListDice())
+		}
+	})
+
+	t.Run("load rejects missing references", func(t *testing.T) {
+		subject := &Set{}
+		err := subject.UnmarshalJSON([]byte(`{"version":1,"dice":[{"name":"a","expression":"@b"},{"name":"b","expression":"heyo"},{"name":"c","expression":"@d+1"},{"name":"d","expression":"1d4"}]}`))
+
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("want *LoadError, got %v", err)
+		}
+		want := []RejectedDice{
+			{Name: "b", Expression: "heyo", Err: ErrInvalidRollExpression},
+			{Name: "a", Expression: "@b", Err: &ReferenceError{Name: "a", Reference: "b"}},
+		}
+		if !reflect.DeepEqual(loadErr.Rejected, want) {
+			t.Errorf("want %v, got %v", want, loadErr.Rejected)
+		}
+		if !reflect.DeepEqual(subject.ListDice(), []string{"c,@d+1", "d,1d4"}) {
+			t.Errorf("want %v, got %v", []string{"c,@d+1", "d,1d4"}, subject.ListDice())
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package dice
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	asciiBarWidth = 40
+	svgWidth      = 640
+	svgHeight     = 360
+	svgMargin     = 48
+)
+
+var (
+	asciiBarSymbols = []string{"#", "*", "+", "o", "=", "x"}
+	svgColors       = []string{"#4e79a7", "#f28e2b", "#59a14f", "#e15759", "#76b7b2", "#b07aa1"}
+)
+
+//PMF is implemented by the results that can be charted, both exact distributions and simulations satisfy it.
+type PMF interface {
+	Outcomes() []int
+	Probability(value int) float64
+	Cumulative(value int) float64
+}
+
+//Series is a labeled set of results to chart, several series are overlaid for comparison.
+type Series struct {
+	Label string
+	Data  PMF
+}
+
+//RenderASCII writes a bar chart of each series to w with a row for every result showing its
+//probability and cumulative probability. Multiple series are interleaved and use different bar symbols.
+//
+//An error is returned if no series are provided or writing fails.
+func RenderASCII(w io.Writer, series ...Series) error {
+	if len(series) == 0 {
+		return ErrNothingToRender
+	}
+
+	values, highest := chartRange(series)
+	labelWidth := len("series")
+	for _, s := range series {
+		labelWidth = max(labelWidth, len(s.Label))
+	}
+
+	var b strings.Builder
+	if len(series) > 1 {
+		for i, s := range series {
+			fmt.Fprintf(&b, "%s %s\n", asciiBarSymbols[i%len(asciiBarSymbols)], s.Label)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%6s  %-*s  %8s  %8s\n", "value", labelWidth, "series", "prob", "cum")
+
+	for _, value := range values {
+		for i, s := range series {
+			probability := s.Data.Probability(value)
+			bar := 0
+			if highest > 0 {
+				bar = int(math.Round(probability / highest * asciiBarWidth))
+			}
+			shown := ""
+			if i == 0 {
+				shown = fmt.Sprint(value)
+			}
+			line := fmt.Sprintf("%6s  %-*s  %7.2f%%  %7.2f%%  %s", shown, labelWidth, s.Label, probability*100, s.Data.Cumulative(value)*100,
+				strings.Repeat(asciiBarSymbols[i%len(asciiBarSymbols)], bar))
+			b.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+//RenderSVG writes a standalone SVG bar chart of each series to w. Multiple series are drawn side by side
+//for every result with a legend identifying them.
+//
+//An error is returned if no series are provided or writing fails.
+func RenderSVG(w io.Writer, series ...Series) error {
+	if len(series) == 0 {
+		return ErrNothingToRender
+	}
+
+	values, highest := chartRange(series)
+	if len(values) == 0 {
+		return ErrNothingToRender
+	}
+	low, high := values[0], values[len(values)-1]
+	if highest == 0 {
+		highest = 1
+	}
+
+	plotWidth := float64(svgWidth - 2*svgMargin)
+	plotHeight := float64(svgHeight - 2*svgMargin)
+	slot := plotWidth / float64(high-low+1)
+	barWidth := slot * 0.8 / float64(len(series))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`+"\n", svgWidth, svgHeight, svgWidth, svgHeight)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", svgWidth, svgHeight)
+
+	//horizontal grid lines with probability labels
+	for i := 0; i <= 4; i++ {
+		y := float64(svgMargin) + plotHeight - plotHeight*float64(i)/4
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.2f" x2="%d" y2="%.2f" stroke="#ddd"/>`+"\n", svgMargin, y, svgWidth-svgMargin, y)
+		fmt.Fprintf(&b, `<text x="%d" y="%.2f" text-anchor="end">%.1f%%</text>`+"\n", svgMargin-4, y+3, highest*float64(i)/4*100)
+	}
+
+	for i, s := range series {
+		color := svgColors[i%len(svgColors)]
+		for _, value := range s.Data.Outcomes() {
+			height := s.Data.Probability(value) / highest * plotHeight
+			x := float64(svgMargin) + slot*float64(value-low) + slot*0.1 + barWidth*float64(i)
+			y := float64(svgMargin) + plotHeight - height
+			fmt.Fprintf(&b, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"><title>%s %d: %.2f%%</title></rect>`+"\n",
+				x, y, barWidth, height, color, html.EscapeString(s.Label), value, s.Data.Probability(value)*100)
+		}
+	}
+
+	//label the x axis, skipping values when there are too many to fit
+	step := int(math.Ceil(float64(high-low+1) / 20))
+	for value := low; value <= high; value += step {
+		x := float64(svgMargin) + slot*(float64(value-low)+0.5)
+		fmt.Fprintf(&b, `<text x="%.2f" y="%d" text-anchor="middle">%d</text>`+"\n", x, svgHeight-svgMargin+14, value)
+	}
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", svgMargin, svgHeight-svgMargin, svgWidth-svgMargin, svgHeight-svgMargin)
+
+	for i, s := range series {
+		y := svgMargin/2 + i*14 - (len(series)-1)*7
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`+"\n", svgWidth-svgMargin-120, y-8, svgColors[i%len(svgColors)])
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", svgWidth-svgMargin-106, y+1, html.EscapeString(s.Label))
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+//chartRange returns every value rolled by any series, lowest first, and the highest probability of any value.
+func chartRange(series []Series) ([]int, float64) {
+	seen := make(map[int]bool)
+	highest := 0.0
+	for _, s := range series {
+		for _, value := range s.Data.Outcomes() {
+			seen[value] = true
+			highest = math.Max(highest, s.Data.Probability(value))
+		}
+	}
+
+	values := make([]int, 0, len(seen))
+	for value := range seen {
+		values = append(values, value)
+	}
+	sort.Ints(values)
+
+	return values, highest
+}
@@ -0,0 +1,137 @@
+package dice
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_RenderASCII(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		want := ` value  series      prob       cum
+     1  1d4       25.00%    25.00%  ########################################
+     2  1d4       25.00%    50.00%  ########################################
+     3  1d4       25.00%    75.00%  ########################################
+     4  1d4       25.00%   100.00%  ########################################
+`
+		d, err := ExactDistribution("1d4")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		var got bytes.Buffer
+		err = RenderASCII(&got, Series{Label: "1d4", Data: d})
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		if got.String() != want {
+			t.Errorf("want\n%s\ngot\n%s", want, got.String())
+		}
+	})
+
+	t.Run("overlay exact and simulated results", func(t *testing.T) {
+		d, err := ExactDistribution("2d6")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		sim, err := Simulate(context.Background(), "1d12", 1000, SimulationOptions{Seed: 3})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		var got bytes.Buffer
+		err = RenderASCII(&got, Series{Label: "2d6", Data: d}, Series{Label: "1d12", Data: sim})
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		if !strings.HasPrefix(got.String(), "# 2d6\n* 1d12\n") {
+			t.Errorf("want legend, got\n%s", got.String())
+		}
+		//a header, a legend of two series plus a blank line, and a row per series for values 1 to 12
+		if lines := strings.Count(got.String(), "\n"); lines != 4+24 {
+			t.Errorf("want %d lines, got %d", 4+24, lines)
+		}
+		if !strings.Contains(got.String(), "     7  2d6       16.67%    58.33%  ") {
+			t.Errorf("want row for 7, got\n%s", got.String())
+		}
+	})
+
+	t.Run("error when nothing to render", func(t *testing.T) {
+		err := RenderASCII(&bytes.Buffer{})
+		if err != ErrNothingToRender {
+			t.Errorf("want %s, got %s", ErrNothingToRender, err)
+		}
+	})
+}
+
+func Test_RenderSVG(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		d, err := ExactDistribution("3d6")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		other, err := ExactDistribution("1d20")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		var got bytes.Buffer
+		err = RenderSVG(&got, Series{Label: "3d6", Data: d}, Series{Label: "<1d20>", Data: other})
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		//must be well formed xml
+		decoder := xml.NewDecoder(strings.NewReader(got.String()))
+		for {
+			_, err := decoder.Token()
+			if err != nil {
+				if err.Error() != "EOF" {
+					t.Errorf("invalid svg, %s", err)
+				}
+				break
+			}
+		}
+
+		if !strings.HasPrefix(got.String(), "<svg ") {
+			t.Errorf("want svg element, got %s", got.String())
+		}
+		//one bar for every result of each series
+		if bars := strings.Count(got.String(), "<title>"); bars != 16+20 {
+			t.Errorf("want %d bars, got %d", 16+20, bars)
+		}
+		if !strings.Contains(got.String(), "&lt;1d20&gt;") {
+			t.Error("want escaped label")
+		}
+	})
+
+	t.Run("error when writing fails", func(t *testing.T) {
+		d, err := ExactDistribution("1d4")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		err = RenderSVG(failingWriter{}, Series{Label: "1d4", Data: d})
+		if err != errWriteFailed {
+			t.Errorf("want %s, got %s", errWriteFailed, err)
+		}
+	})
+
+	t.Run("error when nothing to render", func(t *testing.T) {
+		err := RenderSVG(&bytes.Buffer{})
+		if err != ErrNothingToRender {
+			t.Errorf("want %s, got %s", ErrNothingToRender, err)
+		}
+	})
+}
+
+var errWriteFailed = errors.New("write failed")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWriteFailed }
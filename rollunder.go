@@ -0,0 +1,130 @@
+package dice
+
+import "time"
+
+//SuccessLevel is how well a roll-under challenge succeeded or failed.
+type SuccessLevel int
+
+const (
+	//LevelFumble is a roll in the fumble range.
+	LevelFumble SuccessLevel = iota
+	//LevelFailure is a roll over the target.
+	LevelFailure
+	//LevelRegular is a roll at or under the target.
+	LevelRegular
+	//LevelHard is a roll at or under half the target when levels are graded.
+	LevelHard
+	//LevelExtreme is a roll at or under a fifth of the target when levels are graded.
+	LevelExtreme
+)
+
+func (l SuccessLevel) String() string {
+	switch l {
+	case LevelFumble:
+		return "fumble"
+	case LevelFailure:
+		return "failure"
+	case LevelRegular:
+		return "regular success"
+	case LevelHard:
+		return "hard success"
+	case LevelExtreme:
+		return "extreme success"
+	}
+
+	return "unknown"
+}
+
+//Succeeded returns true for a regular, hard, or extreme success.
+func (l SuccessLevel) Succeeded() bool {
+	return l >= LevelRegular
+}
+
+//RollUnderRules controls how a roll-under challenge is graded.
+type RollUnderRules struct {
+	Graded    bool `json:"graded,omitempty"`    //grade successes as hard at half the target and extreme at a fifth, Call of Cthulhu style
+	Fumble    int  `json:"fumble,omitempty"`    //rolls at or above this fumble, 0 disables, e.g. 100
+	LowFumble int  `json:"lowFumble,omitempty"` //used instead of Fumble when the target is below LowTarget, 0 disables, e.g. 96
+	LowTarget int  `json:"lowTarget,omitempty"` //targets below this use LowFumble, e.g. 50
+}
+
+//CallOfCthulhuRules returns the Call of Cthulhu 7e rules for 1d100 skill rolls, graded successes with a fumble
+//on 100, or on 96-100 when the skill is below 50.
+func CallOfCthulhuRules() RollUnderRules {
+	return RollUnderRules{Graded: true, Fumble: 100, LowFumble: 96, LowTarget: 50}
+}
+
+//RollUnderResult holds a roll-under challenge roll and how well it succeeded.
+type RollUnderResult struct {
+	Level  SuccessLevel
+	Rolls  []int
+	Total  int
+	Target int
+	Margin int //how far under the target the roll was, negative when it was over
+}
+
+//RollUnder rolls an expression against a target, succeeding when the roll is at or under the target,
+//e.g. a Call of Cthulhu skill check, a GURPS 3d6 roll, or an old-school ability check.
+//
+//An error is returned if the expression is not a valid roll expression.
+func RollUnder(expression string, target int, rules RollUnderRules) (RollUnderResult, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return RollUnderResult{}, err
+	}
+
+	rolls, _, total := parsed.roll(New(time.Now().UnixNano()))
+
+	return rules.result(rolls, total, target), nil
+}
+
+//RollUnder rolls the named dice against a target as a roll-under challenge, graded by the entry's RollUnder rules
+//if it has them, or as a plain roll-under challenge if not.
+func (s *Set) RollUnder(name string, target int) (result RollUnderResult, err error) {
+	s.rolling(func(state *setState) []Event {
+		if state.empty() {
+			err = ErrEmptyDiceSet
+			return nil
+		}
+
+		rolls, total, event, rollErr := s.rollAndRecord(state, name, New(time.Now().UnixNano()))
+		if rollErr != nil {
+			err = rollErr
+			return nil
+		}
+
+		var rules RollUnderRules
+		if event.Entry.RollUnder != nil {
+			rules = *event.Entry.RollUnder
+		}
+		result = rules.result(rolls, total, target)
+		return []Event{event}
+	})
+
+	return result, err
+}
+
+//result grades a roll against the target.
+func (r RollUnderRules) result(rolls []int, total int, target int) RollUnderResult {
+	result := RollUnderResult{Rolls: rolls, Total: total, Target: target, Margin: target - total}
+
+	fumble := r.Fumble
+	if target < r.LowTarget {
+		fumble = r.LowFumble
+	}
+
+	switch {
+	case fumble > 0 && total >= fumble:
+		result.Level = LevelFumble
+	case r.Graded && total <= target/5:
+		result.Level = LevelExtreme
+	case r.Graded && total <= target/2:
+		result.Level = LevelHard
+	case total <= target:
+		result.Level = LevelRegular
+	default:
+		result.Level = LevelFailure
+	}
+
+	return result
+}
@@ -0,0 +1,166 @@
+package dice
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRollUnder(t *testing.T) {
+	testCases := []struct {
+		expression string
+		target     int
+		rules      RollUnderRules
+		level      SuccessLevel
+		margin     int
+		err        error
+	}{
+		{
+			expression: "1d1+59",
+			target:     60,
+			level:      LevelRegular,
+		},
+		{
+			expression: "1d1+60",
+			target:     60,
+			level:      LevelFailure,
+			margin:     -1,
+		},
+		{
+			expression: "1d1",
+			target:     60,
+			level:      LevelRegular,
+			margin:     59,
+		},
+		{
+			expression: "1d1+30",
+			target:     60,
+			rules:      CallOfCthulhuRules(),
+			level:      LevelRegular,
+			margin:     29,
+		},
+		{
+			expression: "1d1+29",
+			target:     60,
+			rules:      CallOfCthulhuRules(),
+			level:      LevelHard,
+			margin:     30,
+		},
+		{
+			expression: "1d1+11",
+			target:     60,
+			rules:      CallOfCthulhuRules(),
+			level:      LevelExtreme,
+			margin:     48,
+		},
+		{
+			expression: "1d1+99",
+			target:     60,
+			rules:      CallOfCthulhuRules(),
+			level:      LevelFumble,
+			margin:     -40,
+		},
+		{
+			expression: "1d1+95",
+			target:     60,
+			rules:      CallOfCthulhuRules(),
+			level:      LevelFailure,
+			margin:     -36,
+		},
+		{
+			expression: "1d1+95",
+			target:     40,
+			rules:      CallOfCthulhuRules(),
+			level:      LevelFumble,
+			margin:     -56,
+		},
+		{
+			expression: "1d1+99",
+			target:     120,
+			rules:      CallOfCthulhuRules(),
+			level:      LevelFumble,
+			margin:     20,
+		},
+		{
+			expression: "1d",
+			target:     60,
+			err:        ErrInvalidRollExpression,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d) %s under %d", i, tc.expression, tc.target), func(t *testing.T) {
+			got, err := RollUnder(tc.expression, tc.target, tc.rules)
+			if err != tc.err {
+				t.Fatalf("[err] want %s, got %s", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Level != tc.level {
+				t.Errorf("[level] want %s, got %s", tc.level, got.Level)
+			}
+			if got.Margin != tc.margin {
+				t.Errorf("[margin] want %d, got %d", tc.margin, got.Margin)
+			}
+			if got.Target != tc.target {
+				t.Errorf("[target] want %d, got %d", tc.target, got.Target)
+			}
+		})
+	}
+}
+
+func TestSet_RollUnder(t *testing.T) {
+	t.Run("entry rules are used", func(t *testing.T) {
+		rules := CallOfCthulhuRules()
+		subject := NewSet(map[string]string{"strength": "1d1+9"})
+		_ = subject.AddEntry(Entry{Name: "spot hidden", Expression: "1d1+9", RollUnder: &rules})
+		rules.Graded = false
+
+		got, err := subject.RollUnder("spot hidden", 50)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if got.Level != LevelExtreme || got.Total != 10 {
+			t.Errorf("want extreme success with 10, got %s with %d", got.Level, got.Total)
+		}
+
+		got, _ = subject.RollUnder("strength", 50)
+		if got.Level != LevelRegular {
+			t.Errorf("[no rules] want regular success, got %s", got.Level)
+		}
+
+		if history := subject.History("spot hidden"); len(history) != 1 {
+			t.Errorf("[history] want 1 roll, got %d", len(history))
+		}
+	})
+
+	t.Run("rules are saved", func(t *testing.T) {
+		rules := CallOfCthulhuRules()
+		original := &Set{}
+		_ = original.AddEntry(Entry{Name: "spot hidden", Expression: "1d100", RollUnder: &rules})
+
+		var buf bytes.Buffer
+		_ = original.Save(&buf)
+		loaded := &Set{}
+		if err := loaded.Load(&buf); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		got, _ := loaded.Get("spot hidden")
+		if got.RollUnder == nil || !reflect.DeepEqual(*got.RollUnder, rules) {
+			t.Errorf("want %v, got %v", rules, got.RollUnder)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := (&Set{}).RollUnder("spot hidden", 50); err != ErrEmptyDiceSet {
+			t.Errorf("[err] want %s, got %s", ErrEmptyDiceSet, err)
+		}
+		if _, err := NewSet(map[string]string{"d6": "1d6"}).RollUnder("spot hidden", 50); err != ErrDiceNotFound {
+			t.Errorf("[err] want %s, got %s", ErrDiceNotFound, err)
+		}
+	})
+}
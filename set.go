@@ -3,88 +3,519 @@ package dice
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 //Set holds custom dice that are backed by an expression.
 //You can add dice to your set and roll them as often as needed.
+//
+//Reading and rolling a set does not lock the set. Every change copies the dice and swaps the copy in at once, and
+//every dice remembers its rolls behind its own lock, so a set can be rolled by many goroutines while it is occasionally
+//changed. Only rolls of the same dice wait on each other, to remember their results, and while anyone is subscribed to
+//the set every roll waits its turn to be announced so events keep the order they happened in, see Subscribe.
 type Set struct {
-	m    sync.RWMutex
-	dice map[string]string
+	m     sync.Mutex //serializes changes, readers use the current state without locking
+	state atomic.Pointer[setState]
+
+	history      sync.Map     //name of a dice to its *rollHistory
+	historyLimit atomic.Int64 //zero for the default limit, negative when history is turned off
+
+	em          sync.Mutex //orders events, guarding subscribers and the storing of each change, see rolling
+	subscribers map[*subscriber]bool
+	subscribed  atomic.Int32 //number of subscribers, checked by rolls without locking
+	sequence    uint64
 }
 
-//AddDice will store a roll expression as a custom dice in your set. The name provided can be passed to the RollDice function to roll the expression.
-func (s *Set) AddDice(name string, expression string) error {
-	s.m.Lock()
-	defer s.m.Unlock()
-	if !ValidRollExpression(expression) {
-		return ErrInvalidRollExpression
+//Entry is a custom dice in a set along with information describing it.
+//
+//Besides a roll expression, the expression of an entry can add or subtract other dice in the set by
+//referencing them by name (e.g. "@rapier+@sneak" or "@{main weapon}-2"). A referenced dice must exist when the
+//entry is added, references are resolved every time the entry is rolled.
+type Entry struct {
+	Name        string
+	Expression  string
+	Description string          //e.g. "Longsword attack"
+	Tags        []string        //e.g. attack, spell, save
+	Category    string          //e.g. "weapons"
+	Icon        string          //a hint for displaying the dice, e.g. "sword"
+	RollUnder   *RollUnderRules //rules used when the dice is rolled as a roll-under challenge, e.g. a skill
+	Created     time.Time       //set when the dice is first added
+	Updated     time.Time       //set every time the dice is added or changed
+}
+
+//copy returns the entry with its own copy of the tags and roll-under rules, so changing one does not change the other.
+func (e Entry) copy() Entry {
+	e.Tags = append([]string(nil), e.Tags...)
+	if e.RollUnder != nil {
+		rules := *e.RollUnder
+		e.RollUnder = &rules
 	}
 
-	if s.dice == nil {
-		s.dice = make(map[string]string)
+	return e
+}
+
+//setState is a snapshot of a set. A state is never changed once it is stored, so it can be read without locking.
+type setState struct {
+	dice            map[string]Entry
+	aliases         map[string]string //alias to the name of its dice
+	parent          *Set
+	pinned          *setState //the state of parent to read instead of its current state, see snapshot
+	caseInsensitive bool
+}
+
+//emptyState is the state of a set that has never been changed.
+var emptyState = &setState{}
+
+//load returns the current state of the set.
+func (s *Set) load() *setState {
+	if state := s.state.Load(); state != nil {
+		return state
 	}
 
-	s.dice[name] = expression
+	return emptyState
+}
+
+//snapshot returns the current state with the current state of each parent pinned, so every dice looked up from it,
+//including inherited dice and references, comes from the same snapshot of every layer.
+func (s *Set) snapshot() *setState {
+	return s.load().pin()
+}
+
+//pin returns the state with the current state of each parent pinned, see snapshot.
+func (st *setState) pin() *setState {
+	if st.parent == nil {
+		return st
+	}
+
+	pinned := *st
+	pinned.pinned = st.parent.load().pin()
+
+	return &pinned
+}
+
+//parentState returns the state of the parent, the pinned state if there is one, or nil if there is no parent.
+func (st *setState) parentState() *setState {
+	switch {
+	case st.pinned != nil:
+		return st.pinned
+	case st.parent != nil:
+		return st.parent.load()
+	}
 
 	return nil
 }
 
-//RemoveDice will remove the roll expression saved under the name provided.
-func (s *Set) RemoveDice(name string) {
+//update calls change with a copy of the current state, storing the copy and publishing the returned events
+//unless an error is returned. Changes are serialized so the order of events matches the order of the changes.
+func (s *Set) update(change func(next *setState) ([]Event, error)) error {
 	s.m.Lock()
 	defer s.m.Unlock()
 
-	delete(s.dice, name)
+	current := s.load()
+	next := &setState{
+		dice:            make(map[string]Entry, len(current.dice)),
+		aliases:         make(map[string]string, len(current.aliases)),
+		parent:          current.parent,
+		caseInsensitive: current.caseInsensitive,
+	}
+	for name, entry := range current.dice {
+		next.dice[name] = entry
+	}
+	for alias, name := range current.aliases {
+		next.aliases[alias] = name
+	}
+
+	events, err := change(next)
+	if err != nil {
+		return err
+	}
+
+	s.em.Lock()
+	defer s.em.Unlock()
+	s.state.Store(next)
+	for _, event := range events {
+		s.publish(event)
+	}
+
+	return nil
+}
+
+//AddDice will store a roll expression as a custom dice in your set. The name provided can be passed to the RollDice function to roll the expression.
+//If the name is already in use its expression is replaced and any other information about the dice is kept.
+//
+//The expression can also reference other dice in the set, see Entry for details. An error is returned if doing so
+//would create a cycle of references, or a *ReferenceError if a referenced dice does not exist.
+func (s *Set) AddDice(name string, expression string) error {
+	return s.update(func(next *setState) ([]Event, error) {
+		name := next.canonical(name)
+		if err := next.validate(name, expression); err != nil {
+			return nil, err
+		}
+
+		previous, exists := next.dice[name]
+		entry := previous
+		if !exists {
+			entry = Entry{Name: name}
+		}
+		entry.Expression = expression
+
+		return []Event{putEvent(next.put(entry), previous, exists)}, nil
+	})
+}
+
+//AddEntry will store an entry as a custom dice in your set, replacing any dice with the same name.
+//The created time is kept when replacing a dice unless one is provided, the updated time is always set.
+func (s *Set) AddEntry(entry Entry) error {
+	return s.update(func(next *setState) ([]Event, error) {
+		entry.Name = next.canonical(entry.Name)
+		if err := next.validate(entry.Name, entry.Expression); err != nil {
+			return nil, err
+		}
+
+		previous, exists := next.dice[entry.Name]
+		if exists && entry.Created.IsZero() {
+			entry.Created = previous.Created
+		}
+		entry = entry.copy()
+
+		return []Event{putEvent(next.put(entry), previous, exists)}, nil
+	})
+}
+
+//validate checks that the expression can be stored under name.
+func (st *setState) validate(name string, expression string) error {
+	if _, err := parseSetExpression(expression); err != nil {
+		return err
+	}
+	if err := st.checkReferences(name, expression); err != nil {
+		return err
+	}
+
+	return st.checkResolved(name, expression)
+}
+
+//put stores the entry, stamping its times, and returns what was stored. Only a state that has not been stored yet can be changed.
+func (st *setState) put(entry Entry) Entry {
+	entry.Updated = time.Now()
+	if entry.Created.IsZero() {
+		entry.Created = entry.Updated
+	}
+
+	st.dice[entry.Name] = entry
+
+	return entry
 }
 
-//RollDice rolls the named custom expression and returns its results.
+//RemoveDice will remove the roll expression saved under the name provided along with its aliases. Dice inherited from a parent are not removed.
+//A *DependencyError is returned if other dice reference it, they must be changed or removed first.
+func (s *Set) RemoveDice(name string) error {
+	return s.update(func(next *setState) ([]Event, error) {
+		name := next.canonical(name)
+		if dependents := next.dependents(name); len(dependents) > 0 {
+			return nil, &DependencyError{Name: name, Dependents: dependents}
+		}
+
+		entry, exists := next.dice[name]
+		if !exists {
+			return nil, nil
+		}
+		delete(next.dice, name)
+		next.removeAliases(name)
+		s.ClearHistory(name)
+
+		return []Event{{Type: EventRemoved, Name: name, Entry: entry}}, nil
+	})
+}
+
+//RollDice rolls the named custom expression and returns its results. The roll is remembered in the set's history.
 func (s *Set) RollDice(name string) (rolls []int, sum int, err error) {
-	s.m.RLock()
-	defer s.m.RUnlock()
-	if s.dice == nil || len(s.dice) == 0 {
-		return rolls, sum, ErrEmptyDiceSet
+	s.rolling(func(state *setState) []Event {
+		if state.empty() {
+			err = ErrEmptyDiceSet
+			return nil
+		}
+
+		var event Event
+		rolls, sum, event, err = s.rollAndRecord(state, name, New(time.Now().UnixNano()))
+		if err != nil {
+			return nil
+		}
+		return []Event{event}
+	})
+
+	return rolls, sum, err
+}
+
+//RollResult is the result of rolling one dice as part of a batch.
+type RollResult struct {
+	Name  string
+	Rolls []int
+	Sum   int
+	Err   error //why the dice could not be rolled, the rest of the batch is still rolled
+}
+
+//RollMany rolls each named dice in turn and returns their results in the same order. Every dice is rolled from the
+//same snapshot of the set and its parents, so no other changes to them are seen part way through. Each roll is remembered in the set's history.
+func (s *Set) RollMany(names ...string) []RollResult {
+	results := make([]RollResult, 0, len(names))
+	s.rolling(func(state *setState) []Event {
+		empty := state.empty()
+		seeder := New(time.Now().UnixNano())
+		var events []Event
+		for _, name := range names {
+			result := RollResult{Name: name, Err: ErrEmptyDiceSet}
+			if !empty {
+				var event Event
+				result.Rolls, result.Sum, event, result.Err = s.rollAndRecord(state, name, seeder)
+				if result.Err == nil {
+					events = append(events, event)
+				}
+			}
+			results = append(results, result)
+		}
+		return events
+	})
+
+	return results
+}
+
+//RollRepeat rolls the named dice count times from the same snapshot of the set, see RollMany.
+func (s *Set) RollRepeat(name string, count int) []RollResult {
+	names := make([]string, 0, max(count, 0))
+	for i := 0; i < count; i++ {
+		names = append(names, name)
 	}
 
-	expression := s.dice[name]
+	return s.RollMany(names...)
+}
+
+//rolling calls roll with a snapshot of the set and publishes the events it returns. Nothing is locked while no one is
+//subscribed, otherwise the snapshot is taken and its rolls published while holding the event lock, the same lock changes
+//are stored and published under, so a roll is never announced after a change made to a later snapshot.
+func (s *Set) rolling(roll func(state *setState) []Event) {
+	if s.subscribed.Load() == 0 {
+		roll(s.snapshot())
+		return
+	}
+
+	s.em.Lock()
+	defer s.em.Unlock()
+	for _, event := range roll(s.snapshot()) {
+		s.publish(event)
+	}
+}
 
-	if expression == "" {
-		return rolls, sum, ErrDiceNotFound
+//rollAndRecord rolls the named dice from the state, remembering the roll and returning the event announcing it.
+func (s *Set) rollAndRecord(state *setState, name string, seeder *seeder) ([]int, int, Event, error) {
+	rolls, sum, err := state.roll(name, seeder, make(map[string]bool))
+	if err != nil {
+		return nil, 0, Event{}, err
 	}
 
-	rolls, sum, err = RollExpression(expression)
+	entry, _ := state.lookup(name)
+	record := s.record(entry.Name, rolls, sum)
 
-	return
+	return rolls, sum, Event{Type: EventRolled, Name: entry.Name, Entry: entry, Roll: record}, nil
 }
 
-//ListDice returns a listing of all dice names and expressions in the set.
+//ListDice returns a listing of all dice names and expressions in the set, including any inherited from its parents.
 func (s *Set) ListDice() []string {
-	s.m.RLock()
-	defer s.m.RUnlock()
-	if s.dice == nil || len(s.dice) == 0 {
+	dice := s.load().effective()
+	if len(dice) == 0 {
 		return nil
 	}
 
-	keys := make([]string, 0, len(s.dice))
-	for key := range s.dice {
+	keys := make([]string, 0, len(dice))
+	for key := range dice {
 		keys = append(keys, key)
 	}
 	sort.Strings(keys)
 
 	var list []string
 	for _, k := range keys {
-		list = append(list, fmt.Sprintf("%s,%s", k, s.dice[k]))
+		list = append(list, fmt.Sprintf("%s,%s", k, dice[k].Expression))
 	}
 
 	return list
 }
 
-func NewSet(dice map[string]string) *Set {
+//Get returns the named dice from the set or its parents.
+//An error is returned if the dice does not exist.
+func (s *Set) Get(name string) (Entry, error) {
+	entry, exists := s.load().lookup(name)
+	if !exists {
+		return Entry{}, ErrDiceNotFound
+	}
+
+	return entry.copy(), nil
+}
+
+//Len returns the number of dice in the set, including any inherited from its parents.
+func (s *Set) Len() int {
+	return len(s.load().effective())
+}
+
+//Names returns the name of every dice in the set or its parents, sorted.
+func (s *Set) Names() []string {
+	var names []string
+	for _, entry := range s.Entries() {
+		names = append(names, entry.Name)
+	}
+
+	return names
+}
+
+//Entries returns every dice in the set or its parents, sorted by name.
+func (s *Set) Entries() []Entry {
+	return s.filter(func(Entry) bool { return true })
+}
+
+//Range calls fn for every dice in the set or its parents in order of name until fn returns false.
+//The dice are copied before fn is called, so fn is free to change the set.
+func (s *Set) Range(fn func(entry Entry) bool) {
+	for _, entry := range s.Entries() {
+		if !fn(entry) {
+			return
+		}
+	}
+}
+
+//Rename changes the name of a dice in the set, carrying over its aliases and roll history. References to it from
+//other dice in the set are changed to the new name, references from sets layered on top of it are not.
+//An error is returned if the dice is not in the set or the new name is already in use.
+func (s *Set) Rename(name string, newName string) error {
+	return s.update(func(next *setState) ([]Event, error) {
+		name := next.canonical(name)
+		previous, exists := next.dice[name]
+		if !exists {
+			return nil, ErrDiceNotFound
+		}
+		if key := next.canonical(newName); key != name {
+			if _, taken := next.dice[key]; taken {
+				return nil, ErrDuplicateDice
+			}
+		}
+
+		dependents := next.dependents(name)
+		delete(next.dice, name)
+		delete(next.aliases, newName)
+		entry := previous
+		entry.Name = newName
+		events := []Event{{Type: EventRenamed, Name: newName, Entry: next.put(entry), Previous: previous}}
+
+		for _, dependent := range dependents {
+			old := next.dice[dependent]
+			updated := old
+			updated.Expression = next.renameReferences(old.Expression, name, newName)
+			events = append(events, putEvent(next.put(updated), old, true))
+		}
+
+		for alias, target := range next.aliases {
+			if target == name {
+				next.aliases[alias] = newName
+			}
+		}
+
+		if history, exists := s.history.LoadAndDelete(name); exists {
+			s.history.Store(newName, history)
+		}
+
+		return events, nil
+	})
+}
+
+//Clone returns a copy of the set with the same dice, options, aliases, roll history, and parent.
+//Subscribers are not copied.
+func (s *Set) Clone() *Set {
+	clone := &Set{}
+	clone.state.Store(s.load())
+
+	clone.historyLimit.Store(s.historyLimit.Load())
+	clone.replaceHistories(s.histories())
+
+	return clone
+}
+
+//EntriesWithTag returns every entry in the set or its parents with the provided tag, sorted by name.
+func (s *Set) EntriesWithTag(tag string) []Entry {
+	return s.filter(func(entry Entry) bool {
+		for _, t := range entry.Tags {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+//EntriesInCategory returns every entry in the set or its parents with the provided category, sorted by name.
+func (s *Set) EntriesInCategory(category string) []Entry {
+	return s.filter(func(entry Entry) bool {
+		return entry.Category == category
+	})
+}
+
+//filter returns a copy of every entry that matches, sorted by name.
+func (s *Set) filter(matches func(Entry) bool) []Entry {
+	var entries []Entry
+	for _, entry := range s.load().effective() {
+		if matches(entry) {
+			entries = append(entries, entry.copy())
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	return entries
+}
+
+//sorted returns the entries of this state without its parents, sorted by name.
+func (st *setState) sorted() []Entry {
+	entries := make([]Entry, 0, len(st.dice))
+	for _, entry := range st.dice {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	return entries
+}
+
+//NewSet returns a set holding the provided dice, mapped from name to expression, configured with any options.
+func NewSet(dice map[string]string, opts ...SetOption) *Set {
 	newSet := &Set{}
+	for _, opt := range opts {
+		opt(newSet)
+	}
 
-	for k, v := range dice {
-		_ = newSet.AddDice(k, v)
+	//aliases are checked once the dice are added, drop any named like a dice first so they cannot hide it
+	_ = newSet.update(func(next *setState) ([]Event, error) {
+		for alias := range next.aliases {
+			for name := range dice {
+				if name == alias || next.caseInsensitive && strings.EqualFold(name, alias) {
+					delete(next.aliases, alias)
+					break
+				}
+			}
+		}
+		return nil, nil
+	})
+
+	//dice are added after the dice they reference so the order of the map does not matter
+	entries := make([]Entry, 0, len(dice))
+	for name, expression := range dice {
+		entries = append(entries, Entry{Name: name, Expression: expression})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	for _, entry := range referencesFirst(entries, newSet.load().canonical) {
+		_ = newSet.AddDice(entry.Name, entry.Expression)
 	}
 
+	_ = newSet.update(func(next *setState) ([]Event, error) {
+		next.checkAliases()
+		return nil, nil
+	})
+
 	return newSet
 }
@@ -0,0 +1,205 @@
+package dice
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+//setFormatVersion is written with every saved set so older formats can still be loaded in the future.
+const setFormatVersion = 1
+
+type setJSON struct {
+	Version      int                     `json:"version"`
+	Dice         []entryJSON             `json:"dice"`
+	HistoryLimit int                     `json:"historyLimit,omitempty"`
+	History      map[string][]RollRecord `json:"history,omitempty"`
+	Aliases      map[string]string       `json:"aliases,omitempty"`
+}
+
+type entryJSON struct {
+	Name        string          `json:"name"`
+	Expression  string          `json:"expression"`
+	Description string          `json:"description,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Category    string          `json:"category,omitempty"`
+	Icon        string          `json:"icon,omitempty"`
+	RollUnder   *RollUnderRules `json:"rollUnder,omitempty"`
+	Created     time.Time       `json:"created"`
+	Updated     time.Time       `json:"updated"`
+}
+
+//RejectedDice describes a die, or an alias, that could not be loaded into a set and why.
+type RejectedDice struct {
+	Name       string
+	Expression string
+	AliasOf    string //the name a rejected alias pointed at, empty when a dice was rejected
+	Err        error
+}
+
+//LoadError is returned when some of the dice being loaded into a set were rejected.
+//Every other die is still loaded.
+type LoadError struct {
+	Rejected []RejectedDice
+}
+
+func (e *LoadError) Error() string {
+	var reasons []string
+	for _, r := range e.Rejected {
+		if r.AliasOf != "" {
+			reasons = append(reasons, fmt.Sprintf("alias %q of %q: %s", r.Name, r.AliasOf, r.Err))
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("%q (%s): %s", r.Name, r.Expression, r.Err))
+	}
+
+	return fmt.Sprintf("rejected %d dice: %s", len(e.Rejected), strings.Join(reasons, "; "))
+}
+
+//MarshalJSON encodes the dice in the set as JSON, sorted by name, along with their aliases and roll history.
+func (s *Set) MarshalJSON() ([]byte, error) {
+	state := s.load()
+	encoded := setJSON{Version: setFormatVersion, Dice: []entryJSON{}}
+	for _, entry := range state.sorted() {
+		encoded.Dice = append(encoded.Dice, entryJSON(entry))
+	}
+	if len(state.aliases) > 0 {
+		encoded.Aliases = state.aliases
+	}
+
+	encoded.HistoryLimit = int(s.historyLimit.Load())
+	if histories := s.histories(); len(histories) > 0 {
+		encoded.History = histories
+	}
+
+	return json.Marshal(encoded)
+}
+
+//UnmarshalJSON replaces the dice, aliases, and roll history in the set with those encoded in data. Every expression and alias
+//is validated, if any are rejected the rest are still loaded and a *LoadError listing the rejected dice and aliases is returned.
+func (s *Set) UnmarshalJSON(data []byte) error {
+	var decoded setJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if decoded.Version > setFormatVersion {
+		return ErrUnsupportedVersion
+	}
+
+	dice := make(map[string]Entry)
+	var rejected []RejectedDice
+	for _, entry := range decoded.Dice {
+		if _, exists := dice[entry.Name]; exists {
+			rejected = append(rejected, RejectedDice{Name: entry.Name, Expression: entry.Expression, Err: ErrDuplicateDice})
+			continue
+		}
+		if _, err := parseSetExpression(entry.Expression); err != nil {
+			rejected = append(rejected, RejectedDice{Name: entry.Name, Expression: entry.Expression, Err: err})
+			continue
+		}
+		dice[entry.Name] = Entry(entry).copy()
+	}
+
+	_ = s.update(func(next *setState) ([]Event, error) {
+		previous := next.dice
+		next.dice = dice
+		next.aliases = make(map[string]string, len(decoded.Aliases))
+		for alias, name := range decoded.Aliases {
+			next.aliases[alias] = name
+		}
+
+		//break any cycles of references by rejecting the first dice found in each
+		for _, entry := range decoded.Dice {
+			stored, exists := dice[entry.Name]
+			if !exists || stored.Expression != entry.Expression || !next.reaches(entry.Name, entry.Name, make(map[string]bool)) {
+				continue
+			}
+			delete(dice, entry.Name)
+			rejected = append(rejected, RejectedDice{Name: entry.Name, Expression: entry.Expression, Err: ErrCyclicReference})
+		}
+
+		//reject aliases of dice that were not loaded and dice referencing dice that were not loaded,
+		//either can leave other dice without their references in turn
+		for rejecting := true; rejecting; {
+			dropped := next.checkAliases()
+			rejected = append(rejected, dropped...)
+			rejecting = len(dropped) > 0
+			for _, entry := range decoded.Dice {
+				stored, exists := dice[entry.Name]
+				if !exists || stored.Expression != entry.Expression {
+					continue
+				}
+				if err := next.checkResolved(entry.Name, entry.Expression); err != nil {
+					delete(dice, entry.Name)
+					rejected = append(rejected, RejectedDice{Name: entry.Name, Expression: entry.Expression, Err: err})
+					rejecting = true
+				}
+			}
+		}
+
+		return loadEvents(previous, dice), nil
+	})
+
+	s.historyLimit.Store(int64(decoded.HistoryLimit))
+	s.replaceHistories(decoded.History)
+
+	if len(rejected) > 0 {
+		return &LoadError{Rejected: rejected}
+	}
+
+	return nil
+}
+
+//loadEvents returns the events for every dice removed, added, or replaced by loading.
+func loadEvents(previous map[string]Entry, loaded map[string]Entry) []Event {
+	var events []Event
+	for _, name := range sortedNames(previous) {
+		if _, exists := loaded[name]; !exists {
+			events = append(events, Event{Type: EventRemoved, Name: name, Entry: previous[name]})
+		}
+	}
+
+	for _, name := range sortedNames(loaded) {
+		old, existed := previous[name]
+		events = append(events, putEvent(loaded[name], old, existed))
+	}
+
+	return events
+}
+
+func sortedNames(dice map[string]Entry) []string {
+	names := make([]string, 0, len(dice))
+	for name := range dice {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+//Save writes the dice in the set to w as JSON.
+func (s *Set) Save(w io.Writer) error {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+
+	return err
+}
+
+//Load replaces the dice in the set with the dice saved in r. Every expression is validated,
+//if any dice are rejected the rest are still loaded and a *LoadError listing the rejected dice is returned.
+func (s *Set) Load(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return s.UnmarshalJSON(data)
+}
@@ -0,0 +1,203 @@
+package dice
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSet_MarshalJSON(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		want := `{"version":1,"dice":[` +
+			`{"name":"Dex Save","expression":"1d20+4","created":"2021-03-04T05:06:07Z","updated":"2021-03-04T05:06:07Z"},` +
+			`{"name":"main weapon","expression":"1d20+3","description":"Longsword attack","tags":["attack","melee"],"category":"weapons","icon":"sword","created":"2021-03-04T05:06:07Z","updated":"2022-03-04T05:06:07Z"}]}`
+
+		created := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+		subject := &Set{}
+		subject.state.Store(&setState{dice: map[string]Entry{
+			"Dex Save":    {Name: "Dex Save", Expression: "1d20+4", Created: created, Updated: created},
+			"main weapon": {Name: "main weapon", Expression: "1d20+3", Description: "Longsword attack", Tags: []string{"attack", "melee"}, Category: "weapons", Icon: "sword", Created: created, Updated: created.AddDate(1, 0, 0)},
+		}})
+		got, err := json.Marshal(subject)
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		if string(got) != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	})
+
+	t.Run("empty set", func(t *testing.T) {
+		want := `{"version":1,"dice":[]}`
+
+		got, err := json.Marshal(&Set{})
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		if string(got) != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	})
+}
+
+func TestSet_UnmarshalJSON(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		want := []string{"Dex Save,1d20+4", "main weapon,1d20+3"}
+
+		subject := NewSet(map[string]string{"old": "1d4"})
+		err := json.Unmarshal([]byte(`{"version":1,"dice":[{"name":"main weapon","expression":"1d20+3"},{"name":"Dex Save","expression":"1d20+4"}]}`), subject)
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		got := subject.ListDice()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	})
+
+	t.Run("rejected dice are reported", func(t *testing.T) {
+		wantList := []string{"main weapon,1d20+3"}
+		wantRejected := []RejectedDice{
+			{Name: "broken", Expression: "hey0d20+2", Err: ErrInvalidRollExpression},
+			{Name: "main weapon", Expression: "1d4", Err: ErrDuplicateDice},
+		}
+
+		subject := &Set{}
+		err := json.Unmarshal([]byte(`{"version":1,"dice":[{"name":"main weapon","expression":"1d20+3"},{"name":"broken","expression":"hey0d20+2"},{"name":"main weapon","expression":"1d4"}]}`), subject)
+
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("want *LoadError, got %v", err)
+		}
+		if !reflect.DeepEqual(loadErr.Rejected, wantRejected) {
+			t.Errorf("want %v, got %v", wantRejected, loadErr.Rejected)
+		}
+		if !strings.Contains(err.Error(), `"broken" (hey0d20+2): not a valid roll expression`) {
+			t.Errorf("want rejected dice in message, got %s", err)
+		}
+
+		got := subject.ListDice()
+		if !reflect.DeepEqual(got, wantList) {
+			t.Errorf("want %s, got %s", wantList, got)
+		}
+	})
+
+	t.Run("error when version is unsupported", func(t *testing.T) {
+		subject := &Set{}
+		err := json.Unmarshal([]byte(`{"version":99,"dice":[]}`), subject)
+		if err != ErrUnsupportedVersion {
+			t.Errorf("want %s, got %s", ErrUnsupportedVersion, err)
+		}
+	})
+}
+
+func TestSet_SaveLoad(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		original := NewSet(map[string]string{"main weapon": "1d20+3", "secondary weapon": "3d6", "stats": "4d6-1d4"})
+
+		var buf bytes.Buffer
+		err := original.Save(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		loaded := &Set{}
+		err = loaded.Load(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if !reflect.DeepEqual(loaded.ListDice(), original.ListDice()) {
+			t.Errorf("want %s, got %s", original.ListDice(), loaded.ListDice())
+		}
+	})
+
+	t.Run("entry information is kept", func(t *testing.T) {
+		original := &Set{}
+		err := original.AddEntry(Entry{Name: "fireball", Expression: "8d6", Description: "DEX save for half", Tags: []string{"spell", "save"}, Category: "spells", Icon: "flame"})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		var buf bytes.Buffer
+		err = original.Save(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		loaded := &Set{}
+		err = loaded.Load(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		want, got := original.load().dice["fireball"], loaded.load().dice["fireball"]
+		if !got.Created.Equal(want.Created) || !got.Updated.Equal(want.Updated) {
+			t.Errorf("[times] want %s and %s, got %s and %s", want.Created, want.Updated, got.Created, got.Updated)
+		}
+		want.Created, want.Updated = got.Created, got.Updated
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("aliases are kept", func(t *testing.T) {
+		original := NewSet(map[string]string{"rapier": "1d8+3"}, WithAliases(map[string]string{"r": "rapier"}))
+
+		var buf bytes.Buffer
+		err := original.Save(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		loaded := &Set{}
+		err = loaded.Load(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if got := loaded.Aliases(); !reflect.DeepEqual(got, original.Aliases()) {
+			t.Errorf("want %v, got %v", original.Aliases(), got)
+		}
+	})
+
+	t.Run("aliases of missing dice are rejected", func(t *testing.T) {
+		loaded := &Set{}
+		err := loaded.Load(strings.NewReader(`{"version":1,"dice":[{"name":"rapier","expression":"1d8+3"},{"name":"bad","expression":"heyo"}],` +
+			`"aliases":{"r":"rapier","z":"missing","b":"bad","rapier":"rapier"}}`))
+
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("want *LoadError, got %v", err)
+		}
+		want := []RejectedDice{
+			{Name: "bad", Expression: "heyo", Err: ErrInvalidRollExpression},
+			{Name: "b", AliasOf: "bad", Err: ErrDiceNotFound},
+			{Name: "rapier", AliasOf: "rapier", Err: ErrDuplicateDice},
+			{Name: "z", AliasOf: "missing", Err: ErrDiceNotFound},
+		}
+		if !reflect.DeepEqual(loadErr.Rejected, want) {
+			t.Errorf("want %v, got %v", want, loadErr.Rejected)
+		}
+		if !strings.Contains(err.Error(), `alias "z" of "missing": dice not found`) {
+			t.Errorf("unexpected message, %s", err)
+		}
+		if got := loaded.Aliases(); !reflect.DeepEqual(got, map[string]string{"r": "rapier"}) {
+			t.Errorf("want %v, got %v", map[string]string{"r": "rapier"}, got)
+		}
+	})
+
+	t.Run("error when data is not json", func(t *testing.T) {
+		err := (&Set{}).Load(strings.NewReader("main weapon,1d20+3"))
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -1,27 +1,47 @@
 package dice
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
 func TestSet_AddDice(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
-		want := &Set{
-			dice: map[string]string{
-				"main weapon": "1d20+3",
-			},
-		}
-
 		got := &Set{}
 		err := got.AddDice("main weapon", "1d20+3")
 		if err != nil {
 			t.Errorf("unexpected error, %s", err)
 		}
 
-		if !reflect.DeepEqual(got, want) {
-			t.Errorf("want %v, got %v", want, got)
+		assertEntries(t, got, map[string]string{"main weapon": "1d20+3"})
+	})
+
+	t.Run("replacing keeps entry information", func(t *testing.T) {
+		subject := &Set{}
+		err := subject.AddEntry(Entry{Name: "main weapon", Expression: "1d20+3", Description: "Longsword attack", Tags: []string{"attack"}})
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		created := subject.load().dice["main weapon"].Created
+
+		err = subject.AddDice("main weapon", "1d20+5")
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+
+		got := subject.load().dice["main weapon"]
+		if got.Expression != "1d20+5" {
+			t.Errorf("[expression] want %s, got %s", "1d20+5", got.Expression)
+		}
+		if got.Description != "Longsword attack" || !reflect.DeepEqual(got.Tags, []string{"attack"}) {
+			t.Errorf("[information] want description and tags kept, got %v", got)
+		}
+		if !got.Created.Equal(created) || got.Updated.Before(created) {
+			t.Errorf("[times] want created %s kept and updated after it, got %s and %s", created, got.Created, got.Updated)
 		}
 	})
 
@@ -93,6 +113,101 @@ func TestSet_RollDice(t *testing.T) {
 	})
 }
 
+func TestSet_RollMany(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		subject := NewSet(map[string]string{"attack": "1d1+5", "damage": "2d1+3"})
+
+		got := subject.RollMany("attack", "missing", "damage")
+		want := []RollResult{
+			{Name: "attack", Rolls: []int{1}, Sum: 6},
+			{Name: "missing", Err: ErrDiceNotFound},
+			{Name: "damage", Rolls: []int{1, 1}, Sum: 5},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+
+		if history := subject.History("damage"); len(history) != 1 {
+			t.Errorf("[history] want 1 roll, got %d", len(history))
+		}
+	})
+
+	t.Run("parents are read from one snapshot", func(t *testing.T) {
+		parent := NewSet(map[string]string{"weapon": "1d1", "bonus": "1d1"})
+		subject := NewSet(nil)
+		if err := subject.SetParent(parent); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if err := subject.AddDice("attack", "@weapon+@bonus"); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		done := make(chan struct{})
+		changed := make(chan struct{})
+		go func() {
+			defer close(changed)
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				//both dice change together, so a batch must never see one without the other
+				bag := fmt.Sprintf("weapon: %[1]dd1\nbonus: %[1]dd1", i%2+1)
+				if err := parent.ReadText(strings.NewReader(bag)); err != nil {
+					t.Errorf("unexpected error, %s", err)
+					return
+				}
+			}
+		}()
+
+		var names []string
+		for i := 0; i < 100; i++ {
+			names = append(names, "weapon", "bonus", "attack")
+		}
+		for i := 0; i < 50; i++ {
+			got := subject.RollMany(names...)
+			weapon := got[0].Sum
+			for j, result := range got {
+				want := weapon
+				if result.Name == "attack" {
+					want = 2 * weapon
+				}
+				if result.Err != nil || result.Sum != want {
+					t.Fatalf("[%d] want every dice from one snapshot, got %v at %d", i, result, j)
+				}
+			}
+		}
+		close(done)
+		<-changed
+	})
+
+	t.Run("error when no dice in set", func(t *testing.T) {
+		got := (&Set{}).RollMany("attack")
+		if len(got) != 1 || got[0].Err != ErrEmptyDiceSet {
+			t.Errorf("want %s, got %v", ErrEmptyDiceSet, got)
+		}
+	})
+}
+
+func TestSet_RollRepeat(t *testing.T) {
+	subject := NewSet(map[string]string{"d6": "1d6"})
+
+	got := subject.RollRepeat("d6", 4)
+	if len(got) != 4 {
+		t.Fatalf("[len] want 4, got %d", len(got))
+	}
+	for _, result := range got {
+		if result.Err != nil || result.Name != "d6" || result.Sum < 1 || result.Sum > 6 {
+			t.Errorf("unexpected result %v", result)
+		}
+	}
+
+	if got := subject.RollRepeat("d6", 0); len(got) != 0 {
+		t.Errorf("[none] want no results, got %v", got)
+	}
+}
+
 func TestSet_ListDice(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		want := []string{"Dex Save,1d20+4", "main weapon,1d20+3", "secondary weapon,3d6"}
@@ -134,33 +249,274 @@ func TestSet_RemoveDice(t *testing.T) {
 			t.Errorf("unexpected error, %s", err)
 		}
 
-		if len(subject.dice) != 1 {
-			t.Errorf("want 1, got %d", len(subject.dice))
+		if len(subject.load().dice) != 1 {
+			t.Errorf("want 1, got %d", len(subject.load().dice))
 		}
 
 		subject.RemoveDice("main weapon")
 
-		if len(subject.dice) != 0 {
-			t.Errorf("want 0, got %d", len(subject.dice))
+		if len(subject.load().dice) != 0 {
+			t.Errorf("want 0, got %d", len(subject.load().dice))
 		}
 	})
 }
 
 func Test_NewSet(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
-		want := &Set{
-			dice: map[string]string{
-				"main weapon": "1d20+3",
-			},
+		got := NewSet(map[string]string{"main weapon": "1d20+3"})
+		assertEntries(t, got, map[string]string{"main weapon": "1d20+3"})
+	})
+}
+
+func TestSet_AddEntry(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		subject := &Set{}
+		entry := Entry{Name: "longsword", Expression: "1d8+3", Description: "Longsword attack", Tags: []string{"attack", "melee"}, Category: "weapons", Icon: "sword"}
+		err := subject.AddEntry(entry)
+		if err != nil {
+			t.Errorf("unexpected error, %s", err)
 		}
 
-		got := NewSet(map[string]string{"main weapon": "1d20+3"})
-		if !reflect.DeepEqual(got, want) {
+		got := subject.load().dice["longsword"]
+		if got.Created.IsZero() || got.Updated.IsZero() {
+			t.Errorf("[times] want created and updated set, got %s and %s", got.Created, got.Updated)
+		}
+		entry.Created, entry.Updated = got.Created, got.Updated
+		if !reflect.DeepEqual(got, entry) {
+			t.Errorf("want %v, got %v", entry, got)
+		}
+
+		//the set keeps its own copy of the tags
+		entry.Tags[0] = "changed"
+		if subject.load().dice["longsword"].Tags[0] != "attack" {
+			t.Errorf("[tags] want %s, got %s", "attack", subject.load().dice["longsword"].Tags[0])
+		}
+	})
+
+	t.Run("error when expression is invalid", func(t *testing.T) {
+		subject := &Set{}
+		err := subject.AddEntry(Entry{Name: "broken", Expression: "hey0d20+2"})
+		if err != ErrInvalidRollExpression {
+			t.Errorf("want %s, got %s", ErrInvalidRollExpression, err)
+		}
+	})
+}
+
+func TestSet_EntriesWithTag(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		subject := &Set{}
+		_ = subject.AddEntry(Entry{Name: "longsword", Expression: "1d8+3", Tags: []string{"attack", "melee"}, Category: "weapons"})
+		_ = subject.AddEntry(Entry{Name: "fireball", Expression: "8d6", Tags: []string{"spell", "save"}, Category: "spells"})
+		_ = subject.AddEntry(Entry{Name: "firebolt", Expression: "2d10", Tags: []string{"spell", "attack"}, Category: "spells"})
+		_ = subject.AddDice("dex save", "1d20+2")
+
+		assertEntryNames(t, subject.EntriesWithTag("attack"), []string{"firebolt", "longsword"})
+		assertEntryNames(t, subject.EntriesWithTag("save"), []string{"fireball"})
+		assertEntryNames(t, subject.EntriesWithTag("nope"), nil)
+		assertEntryNames(t, subject.EntriesInCategory("spells"), []string{"fireball", "firebolt"})
+		assertEntryNames(t, subject.EntriesInCategory(""), []string{"dex save"})
+	})
+}
+
+func TestSet_Get(t *testing.T) {
+	parent := NewSet(map[string]string{"initiative": "1d20+2"})
+	subject := NewSet(map[string]string{"main weapon": "1d20+3"})
+	_ = subject.SetParent(parent)
+
+	got, err := subject.Get("initiative")
+	if err != nil || got.Expression != "1d20+2" {
+		t.Errorf("want 1d20+2, got %v (%v)", got, err)
+	}
+
+	_, err = subject.Get("missing")
+	if err != ErrDiceNotFound {
+		t.Errorf("want error %s, got %v", ErrDiceNotFound, err)
+	}
+}
+
+func TestSet_listing(t *testing.T) {
+	parent := NewSet(map[string]string{"initiative": "1d20+2", "main weapon": "1d20"})
+	subject := NewSet(map[string]string{"main weapon": "1d20+3", "dagger, offhand": "1d4"})
+	_ = subject.SetParent(parent)
+
+	if got := subject.Len(); got != 3 {
+		t.Errorf("[len] want 3, got %d", got)
+	}
+
+	want := []string{"dagger, offhand", "initiative", "main weapon"}
+	if got := subject.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("[names] want %v, got %v", want, got)
+	}
+
+	entries := subject.Entries()
+	assertEntryNames(t, entries, want)
+	if entries[2].Expression != "1d20+3" {
+		t.Errorf("[entries] want 1d20+3, got %s", entries[2].Expression)
+	}
+
+	var ranged []string
+	subject.Range(func(entry Entry) bool {
+		ranged = append(ranged, entry.Name)
+		_ = subject.AddDice("added while ranging", "1d6")
+		return len(ranged) < 2
+	})
+	if !reflect.DeepEqual(ranged, want[:2]) {
+		t.Errorf("[range] want %v, got %v", want[:2], ranged)
+	}
+
+	if got := NewSet(nil).Names(); got != nil {
+		t.Errorf("[empty] want nil, got %v", got)
+	}
+}
+
+func TestSet_Rename(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		subject := NewSet(map[string]string{"rapier": "1d8+3", "sneak": "2d6", "attack": "@rapier+@sneak-1"})
+		_ = subject.AddAlias("r", "rapier")
+		_, _, _ = subject.RollDice("rapier")
+
+		if err := subject.Rename("rapier", "main weapon"); err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		assertEntries(t, subject, map[string]string{"main weapon": "1d8+3", "sneak": "2d6", "attack": "@{main weapon}+@sneak-1"})
+		if got := subject.Aliases(); !reflect.DeepEqual(got, map[string]string{"r": "main weapon"}) {
+			t.Errorf("[aliases] want r -> main weapon, got %v", got)
+		}
+		if got := subject.History("main weapon"); len(got) != 1 {
+			t.Errorf("[history] want 1 roll, got %d", len(got))
+		}
+		if _, _, err := subject.RollDice("attack"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		subject := NewSet(map[string]string{"rapier": "1d8+3", "sneak": "2d6"})
+		if err := subject.Rename("missing", "other"); err != ErrDiceNotFound {
+			t.Errorf("want error %s, got %v", ErrDiceNotFound, err)
+		}
+		if err := subject.Rename("rapier", "sneak"); err != ErrDuplicateDice {
+			t.Errorf("want error %s, got %v", ErrDuplicateDice, err)
+		}
+	})
+}
+
+func TestSet_Clone(t *testing.T) {
+	subject := NewSet(map[string]string{"rapier": "1d8+3"}, CaseInsensitive(), WithAliases(map[string]string{"r": "rapier"}))
+	_ = subject.AddEntry(Entry{Name: "dagger", Expression: "1d4", Tags: []string{"attack"}})
+	_, _, _ = subject.RollDice("rapier")
+
+	clone := subject.Clone()
+	_ = subject.AddDice("RAPIER", "1d8")
+	_ = subject.AddEntry(Entry{Name: "dagger", Expression: "1d4", Tags: []string{"changed"}})
+	_, _, _ = subject.RollDice("rapier")
+
+	assertEntries(t, clone, map[string]string{"rapier": "1d8+3", "dagger": "1d4"})
+	if got, _ := clone.Get("Dagger"); !reflect.DeepEqual(got.Tags, []string{"attack"}) {
+		t.Errorf("[tags] want [attack], got %v", got.Tags)
+	}
+	if got, err := clone.Get("R"); err != nil || got.Name != "rapier" {
+		t.Errorf("[alias] want rapier, got %v (%v)", got, err)
+	}
+	if got := clone.History("rapier"); len(got) != 1 {
+		t.Errorf("[history] want 1 roll, got %d", len(got))
+	}
+}
+
+func TestSet_options(t *testing.T) {
+	t.Run("case insensitive", func(t *testing.T) {
+		subject := NewSet(map[string]string{"Fireball": "8d6"}, CaseInsensitive())
+		_ = subject.AddDice("FIREBALL", "10d6")
+		_ = subject.AddDice("attack", "@fireball+1")
+
+		assertEntries(t, subject, map[string]string{"Fireball": "10d6", "attack": "@fireball+1"})
+		if _, _, err := subject.RollDice("fireBALL"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if err := subject.RemoveDice("FIREBALL"); !errors.Is(err, ErrDiceInUse) {
+			t.Errorf("want error %s, got %v", ErrDiceInUse, err)
+		}
+
+		if _, err := NewSet(map[string]string{"Fireball": "8d6"}).Get("fireball"); err != ErrDiceNotFound {
+			t.Errorf("[sensitive] want error %s, got %v", ErrDiceNotFound, err)
+		}
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		parent := NewSet(map[string]string{"initiative": "1d20+2"})
+		subject := NewSet(map[string]string{"rapier": "1d8+3"}, WithAliases(map[string]string{"r": "rapier"}))
+		_ = subject.SetParent(parent)
+
+		if err := subject.AddAlias("init", "initiative"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		if err := subject.AddAlias("rapier", "initiative"); err != ErrDuplicateDice {
+			t.Errorf("want error %s, got %v", ErrDuplicateDice, err)
+		}
+		if err := subject.AddAlias("x", "missing"); err != ErrDiceNotFound {
+			t.Errorf("want error %s, got %v", ErrDiceNotFound, err)
+		}
+
+		_ = subject.AddDice("attack", "@r+@init")
+		if _, _, err := subject.RollDice("attack"); err != nil {
+			t.Errorf("unexpected error, %s", err)
+		}
+		_, _, _ = subject.RollDice("r")
+		if got := subject.History("rapier"); len(got) != 1 {
+			t.Errorf("[history] want 1 roll, got %d", len(got))
+		}
+
+		_ = subject.RemoveDice("attack")
+		_ = subject.RemoveDice("r")
+		want := map[string]string{"init": "initiative"}
+		if got := subject.Aliases(); !reflect.DeepEqual(got, want) {
 			t.Errorf("want %v, got %v", want, got)
 		}
+
+		subject.RemoveAlias("init")
+		if got := subject.Aliases(); len(got) != 0 {
+			t.Errorf("want no aliases, got %v", got)
+		}
+	})
+
+	t.Run("invalid aliases are dropped", func(t *testing.T) {
+		subject := NewSet(map[string]string{"rapier": "1d8+3", "attack": "@r+1"},
+			WithAliases(map[string]string{"r": "rapier", "z": "missing", "attack": "rapier"}))
+
+		if got := subject.Aliases(); !reflect.DeepEqual(got, map[string]string{"r": "rapier"}) {
+			t.Errorf("want %v, got %v", map[string]string{"r": "rapier"}, got)
+		}
+		if subject.Len() != 2 {
+			t.Errorf("want dice referencing an alias to be added, got %v", subject.ListDice())
+		}
 	})
 }
 
+func assertEntries(t *testing.T, s *Set, want map[string]string) {
+	t.Helper()
+	if len(s.load().dice) != len(want) {
+		t.Errorf("[len] want %d, got %d", len(want), len(s.load().dice))
+	}
+	for name, expression := range want {
+		entry, exists := s.load().dice[name]
+		if !exists || entry.Name != name || entry.Expression != expression {
+			t.Errorf("want %s,%s, got %v", name, expression, entry)
+		}
+	}
+}
+
+func assertEntryNames(t *testing.T, entries []Entry, want []string) {
+	t.Helper()
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
 func Test_race(t *testing.T) {
 	subject := Set{}
 	go func() {
@@ -187,3 +543,31 @@ func Test_race(t *testing.T) {
 		}
 	}()
 }
+
+func Test_race_snapshots(t *testing.T) {
+	subject := NewSet(map[string]string{"attack": "1d20+5", "damage": "@weapon+3", "weapon": "1d8"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				for _, result := range subject.RollMany("attack", "damage") {
+					if result.Err != nil {
+						t.Errorf("unexpected error, %s", result.Err)
+					}
+				}
+				_ = subject.Names()
+				_, _ = subject.Stats("damage")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			_ = subject.AddDice("weapon", fmt.Sprintf("1d%d", i+2))
+			_ = subject.AddDice(fmt.Sprintf("extra %d", i), "@weapon")
+			_ = subject.RemoveDice(fmt.Sprintf("extra %d", i))
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,216 @@
+package dice
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	//shareCodeVersion is the first byte of every share code so the encoding can change in the future.
+	//Version 2 added roll-under rules, codes of either version can be parsed.
+	shareCodeVersion = 2
+	//maxSharedSize limits how large a share code can expand to when decoded.
+	maxSharedSize = 1 << 20
+)
+
+//ShareCode encodes the dice in the set into a short URL-safe string that can be passed to ParseShareCode.
+//The name, expression, description, tags, category, icon, and roll-under rules of every dice are kept, inherited dice
+//and history are not.
+//
+//The code is a version byte followed by the compressed dice and a checksum, encoded as unpadded base64url.
+func (s *Set) ShareCode() (string, error) {
+	entries := s.load().sorted()
+
+	var payload bytes.Buffer
+	writeShareUvarint(&payload, uint64(len(entries)))
+	for _, entry := range entries {
+		for _, field := range []string{entry.Name, entry.Expression, entry.Description, entry.Category, entry.Icon} {
+			writeShareString(&payload, field)
+		}
+		writeShareUvarint(&payload, uint64(len(entry.Tags)))
+		for _, tag := range entry.Tags {
+			writeShareString(&payload, tag)
+		}
+
+		if entry.RollUnder == nil {
+			writeShareUvarint(&payload, 0)
+			continue
+		}
+		rules := entry.RollUnder
+		graded := uint64(0)
+		if rules.Graded {
+			graded = 1
+		}
+		writeShareUvarint(&payload, 1)
+		writeShareUvarint(&payload, graded)
+		for _, value := range []int{rules.Fumble, rules.LowFumble, rules.LowTarget} {
+			payload.Write(binary.AppendVarint(nil, int64(value)))
+		}
+	}
+
+	var code bytes.Buffer
+	code.WriteByte(shareCodeVersion)
+	compressor, err := flate.NewWriter(&code, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := compressor.Write(payload.Bytes()); err != nil {
+		return "", err
+	}
+	if err := compressor.Close(); err != nil {
+		return "", err
+	}
+	code.Write(binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(code.Bytes())))
+
+	return base64.RawURLEncoding.EncodeToString(code.Bytes()), nil
+}
+
+//ParseShareCode rebuilds a set from a code returned by ShareCode, validating every dice.
+//
+//An error wrapping ErrInvalidShareCode describes why a code that was mistyped, truncated, or changed is rejected.
+//ErrUnsupportedVersion is returned for codes made by a newer version of this package.
+func ParseShareCode(code string) (*Set, error) {
+	data, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: contains characters that are not base64url", ErrInvalidShareCode)
+	}
+	if len(data) < 1+4 {
+		return nil, fmt.Errorf("%w: too short, it may have been truncated", ErrInvalidShareCode)
+	}
+
+	body, checksum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, fmt.Errorf("%w: checksum does not match, it may have been changed or truncated", ErrInvalidShareCode)
+	}
+	version := body[0]
+	if version < 1 || version > shareCodeVersion {
+		return nil, ErrUnsupportedVersion
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(body[1:])), maxSharedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: dice could not be decompressed", ErrInvalidShareCode)
+	}
+	if len(payload) > maxSharedSize {
+		return nil, fmt.Errorf("%w: dice are too large", ErrInvalidShareCode)
+	}
+
+	entries, err := readShareEntries(bufio.NewReader(bytes.NewReader(payload)), version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dice could not be read", ErrInvalidShareCode)
+	}
+
+	s := &Set{}
+	for _, entry := range referencesFirst(entries, s.load().canonical) {
+		if _, exists := s.load().dice[entry.Name]; exists {
+			return nil, fmt.Errorf("%w: dice %q: %w", ErrInvalidShareCode, entry.Name, ErrDuplicateDice)
+		}
+		if err := s.AddEntry(entry); err != nil {
+			return nil, fmt.Errorf("%w: dice %q: %w", ErrInvalidShareCode, entry.Name, err)
+		}
+	}
+
+	return s, nil
+}
+
+//readShareEntries reads the dice written by ShareCode in the provided version, every byte must be used.
+func readShareEntries(r *bufio.Reader, version byte) ([]Entry, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for i := uint64(0); i < count; i++ {
+		var fields [5]string
+		for f := range fields {
+			if fields[f], err = readShareString(r); err != nil {
+				return nil, err
+			}
+		}
+		entry := Entry{Name: fields[0], Expression: fields[1], Description: fields[2], Category: fields[3], Icon: fields[4]}
+
+		tags, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		for t := uint64(0); t < tags; t++ {
+			tag, err := readShareString(r)
+			if err != nil {
+				return nil, err
+			}
+			entry.Tags = append(entry.Tags, tag)
+		}
+
+		if version >= 2 {
+			if entry.RollUnder, err = readShareRollUnder(r); err != nil {
+				return nil, err
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if _, err := r.ReadByte(); err != io.EOF {
+		return nil, ErrInvalidShareCode
+	}
+
+	return entries, nil
+}
+
+//readShareRollUnder reads the roll-under rules of a dice, nil if it has none.
+func readShareRollUnder(r *bufio.Reader) (*RollUnderRules, error) {
+	present, err := binary.ReadUvarint(r)
+	if err != nil || present == 0 {
+		return nil, err
+	}
+
+	graded, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if present != 1 || graded > 1 {
+		return nil, ErrInvalidShareCode
+	}
+
+	var values [3]int64
+	for i := range values {
+		if values[i], err = binary.ReadVarint(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return &RollUnderRules{Graded: graded == 1, Fumble: int(values[0]), LowFumble: int(values[1]), LowTarget: int(values[2])}, nil
+}
+
+func writeShareUvarint(b *bytes.Buffer, value uint64) {
+	b.Write(binary.AppendUvarint(nil, value))
+}
+
+func writeShareString(b *bytes.Buffer, value string) {
+	writeShareUvarint(b, uint64(len(value)))
+	b.WriteString(value)
+}
+
+func readShareString(r *bufio.Reader) (string, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if length > maxSharedSize {
+		return "", ErrInvalidShareCode
+	}
+
+	value := make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
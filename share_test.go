@@ -0,0 +1,119 @@
+package dice
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"hash/crc32"
+	"reflect"
+	"testing"
+)
+
+func TestSet_ShareCode(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		original := NewSet(map[string]string{"magic missile": "3d4+3", "attack": "@{magic missile}+1"})
+		_ = original.AddEntry(Entry{Name: "fireball", Expression: "8d6", Description: "DEX save", Tags: []string{"spell", "save"}, Category: "spells", Icon: "flame"})
+		rules := RollUnderRules{Graded: true, Fumble: 100, LowFumble: 96, LowTarget: -1}
+		_ = original.AddEntry(Entry{Name: "spot", Expression: "1d100", RollUnder: &rules})
+
+		code, err := original.ShareCode()
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if _, err := base64.RawURLEncoding.DecodeString(code); err != nil {
+			t.Errorf("code is not base64url, %s", err)
+		}
+
+		got, err := ParseShareCode(code)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		if !reflect.DeepEqual(got.ListDice(), original.ListDice()) {
+			t.Errorf("want %s, got %s", original.ListDice(), got.ListDice())
+		}
+		fireball, _ := got.Get("fireball")
+		if fireball.Description != "DEX save" || fireball.Category != "spells" || fireball.Icon != "flame" || !reflect.DeepEqual(fireball.Tags, []string{"spell", "save"}) {
+			t.Errorf("entry information was not kept, got %v", fireball)
+		}
+		if fireball.RollUnder != nil {
+			t.Errorf("[fireball] want no roll-under rules, got %v", fireball.RollUnder)
+		}
+		if spot, _ := got.Get("spot"); spot.RollUnder == nil || *spot.RollUnder != rules {
+			t.Errorf("[spot] want roll-under rules %v, got %v", rules, spot.RollUnder)
+		}
+	})
+
+	t.Run("version 1 codes can be parsed", func(t *testing.T) {
+		var payload bytes.Buffer
+		writeShareUvarint(&payload, 1)
+		for _, field := range []string{"fireball", "8d6", "DEX save", "spells", "flame"} {
+			writeShareString(&payload, field)
+		}
+		writeShareUvarint(&payload, 1)
+		writeShareString(&payload, "spell")
+
+		body := bytes.NewBuffer([]byte{1})
+		compressor, _ := flate.NewWriter(body, flate.BestCompression)
+		_, _ = compressor.Write(payload.Bytes())
+		_ = compressor.Close()
+
+		got, err := ParseShareCode(base64.RawURLEncoding.EncodeToString(withChecksum(body.Bytes())))
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		fireball, _ := got.Get("fireball")
+		if fireball.Expression != "8d6" || fireball.Icon != "flame" || !reflect.DeepEqual(fireball.Tags, []string{"spell"}) || fireball.RollUnder != nil {
+			t.Errorf("unexpected entry %+v", fireball)
+		}
+	})
+
+	t.Run("empty set", func(t *testing.T) {
+		code, err := (&Set{}).ShareCode()
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		got, err := ParseShareCode(code)
+		if err != nil || got.Len() != 0 {
+			t.Errorf("want empty set, got %v (%v)", got.ListDice(), err)
+		}
+	})
+}
+
+func TestParseShareCode(t *testing.T) {
+	code, _ := NewSet(map[string]string{"fireball": "8d6", "magic missile": "3d4+3"}).ShareCode()
+	data, _ := base64.RawURLEncoding.DecodeString(code)
+
+	tampered := append([]byte(nil), data...)
+	tampered[2] ^= 0xff
+
+	newer := append([]byte(nil), data...)
+	newer[0] = shareCodeVersion + 1
+
+	tests := map[string]struct {
+		code    string
+		wantErr error
+	}{
+		"not base64url":  {code: code[:4] + "*" + code[5:], wantErr: ErrInvalidShareCode},
+		"empty":          {code: "", wantErr: ErrInvalidShareCode},
+		"truncated":      {code: code[:len(code)-3], wantErr: ErrInvalidShareCode},
+		"tampered":       {code: base64.RawURLEncoding.EncodeToString(tampered), wantErr: ErrInvalidShareCode},
+		"newer version":  {code: base64.RawURLEncoding.EncodeToString(withChecksum(newer[:len(newer)-4])), wantErr: ErrUnsupportedVersion},
+		"not compressed": {code: base64.RawURLEncoding.EncodeToString(withChecksum([]byte{shareCodeVersion, 0xff, 0xff})), wantErr: ErrInvalidShareCode},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseShareCode(test.code)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("want error %s, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func withChecksum(body []byte) []byte {
+	checksum := crc32.ChecksumIEEE(body)
+	return append(append([]byte(nil), body...), byte(checksum>>24), byte(checksum>>16), byte(checksum>>8), byte(checksum))
+}
@@ -0,0 +1,224 @@
+package dice
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	//simulationChunkSize is the number of trials each independently seeded stream rolls.
+	simulationChunkSize = 10000
+	//defaultConfidence is the confidence level used for intervals when one is not provided.
+	defaultConfidence = 0.95
+)
+
+//SimulationOptions controls how a simulation is run.
+type SimulationOptions struct {
+	Workers    int     //number of goroutines rolling, defaults to the number of CPUs
+	Seed       int64   //master seed, the same seed always produces the same results, 0 picks a random seed
+	Confidence float64 //confidence level (0-1) used for intervals, defaults to 0.95
+}
+
+//Interval is a range of values expected to contain the true value at the simulation's confidence level.
+type Interval struct {
+	Low  float64
+	High float64
+}
+
+//Simulation holds the results of rolling an expression many times.
+type Simulation struct {
+	Trials       int
+	Seed         int64 //master seed used, pass it back in the options to reproduce the results
+	Confidence   float64
+	Histogram    map[int]int64 //number of times each result was rolled
+	Mean         float64
+	Variance     float64
+	StdDev       float64
+	Min          int
+	Max          int
+	MeanInterval Interval
+}
+
+//Simulate rolls the provided expression the requested number of times across worker goroutines.
+//Trials are split into chunks that each roll from their own stream seeded from the master seed,
+//so a given seed always produces the same results no matter how many workers are used.
+//
+//An error is returned if the expression is not a valid roll expression, trials is less than one,
+//or the context is done before the simulation finishes.
+func Simulate(ctx context.Context, expression string, trials int, opts SimulationOptions) (*Simulation, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed.simulate(ctx, trials, opts)
+}
+
+//Outcomes returns every result that was rolled, lowest first.
+func (s *Simulation) Outcomes() []int {
+	outcomes := make([]int, 0, len(s.Histogram))
+	for value := range s.Histogram {
+		outcomes = append(outcomes, value)
+	}
+	sort.Ints(outcomes)
+
+	return outcomes
+}
+
+//Probability returns the fraction of trials that rolled the provided value.
+func (s *Simulation) Probability(value int) float64 {
+	return float64(s.Histogram[value]) / float64(s.Trials)
+}
+
+//Cumulative returns the fraction of trials that rolled the provided value or lower.
+func (s *Simulation) Cumulative(value int) float64 {
+	var count int64
+	for rolled, c := range s.Histogram {
+		if rolled <= value {
+			count += c
+		}
+	}
+
+	return float64(count) / float64(s.Trials)
+}
+
+//ProbabilityInterval returns the Wilson score interval for the probability of rolling the provided value.
+func (s *Simulation) ProbabilityInterval(value int) Interval {
+	z := zScore(s.Confidence)
+	n := float64(s.Trials)
+	p := s.Probability(value)
+
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	spread := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+
+	return Interval{Low: math.Max(0, center-spread), High: math.Min(1, center+spread)}
+}
+
+//Distribution returns the simulated results as an estimated distribution.
+func (s *Simulation) Distribution() *Distribution {
+	d := newDistribution(s.Min, s.Max)
+	for value, count := range s.Histogram {
+		d.counts[value-s.Min].SetInt64(count)
+	}
+	d.total.SetInt64(int64(s.Trials))
+	d.exact = false
+
+	return d
+}
+
+//simulate rolls the parsed expression trials times, see Simulate for details.
+func (p parsedExpression) simulate(ctx context.Context, trials int, opts SimulationOptions) (*Simulation, error) {
+	if trials < 1 {
+		return nil, ErrInvalidTrials
+	}
+
+	if opts.Workers < 1 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	if opts.Confidence <= 0 || opts.Confidence >= 1 {
+		opts.Confidence = defaultConfidence
+	}
+
+	//workers take the next chunk from a shared counter so no work has to be queued up front
+	chunks := trials / simulationChunkSize
+	if trials%simulationChunkSize != 0 {
+		chunks++
+	}
+	var next atomic.Int64
+
+	var m sync.Mutex
+	histogram := make(map[int]int64)
+	var wg sync.WaitGroup
+	for w := 0; w < min(opts.Workers, chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				chunk := int(next.Add(1) - 1)
+				if chunk >= chunks || ctx.Err() != nil {
+					return
+				}
+
+				size := min(simulationChunkSize, trials-chunk*simulationChunkSize)
+				s := New(chunkSeed(opts.Seed, chunk))
+				results := make(map[int]int64)
+				for i := 0; i < size; i++ {
+					_, _, sum := p.roll(s)
+					results[sum]++
+				}
+
+				m.Lock()
+				for value, count := range results {
+					histogram[value] += count
+				}
+				m.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return newSimulation(histogram, trials, opts), nil
+}
+
+//newSimulation calculates the moments and intervals of a finished histogram.
+func newSimulation(histogram map[int]int64, trials int, opts SimulationOptions) *Simulation {
+	sim := &Simulation{
+		Trials:     trials,
+		Seed:       opts.Seed,
+		Confidence: opts.Confidence,
+		Histogram:  histogram,
+	}
+
+	//exact sums keep the moments identical regardless of the order results were merged
+	sum, squares := new(big.Int), new(big.Int)
+	for i, value := range sim.Outcomes() {
+		if i == 0 {
+			sim.Min = value
+		}
+		sim.Max = value
+		v := big.NewInt(int64(value))
+		count := big.NewInt(histogram[value])
+		sum.Add(sum, new(big.Int).Mul(v, count))
+		squares.Add(squares, new(big.Int).Mul(new(big.Int).Mul(v, v), count))
+	}
+
+	n := big.NewInt(int64(trials))
+	mean := new(big.Rat).SetFrac(sum, n)
+	variance := new(big.Rat).SetFrac(squares, n)
+	variance.Sub(variance, new(big.Rat).Mul(mean, mean))
+	sim.Mean, _ = mean.Float64()
+	sim.Variance, _ = variance.Float64()
+	sim.StdDev = math.Sqrt(sim.Variance)
+
+	spread := zScore(sim.Confidence) * sim.StdDev / math.Sqrt(float64(trials))
+	sim.MeanInterval = Interval{Low: sim.Mean - spread, High: sim.Mean + spread}
+
+	return sim
+}
+
+//chunkSeed derives an independent seed for a chunk of trials from the master seed (splitmix64).
+func chunkSeed(seed int64, chunk int) int64 {
+	z := uint64(seed) + uint64(chunk+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+	return int64(z ^ (z >> 31))
+}
+
+//zScore returns the two-sided standard normal critical value for the confidence level.
+func zScore(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
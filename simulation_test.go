@@ -0,0 +1,123 @@
+package dice
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_Simulate(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		got, err := Simulate(context.Background(), "2d6+1", 50000, SimulationOptions{Seed: 42})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if got.Trials != 50000 {
+			t.Errorf("[trials] want 50000, got %d", got.Trials)
+		}
+		if got.Seed != 42 {
+			t.Errorf("[seed] want 42, got %d", got.Seed)
+		}
+		if got.Confidence != defaultConfidence {
+			t.Errorf("[confidence] want %f, got %f", defaultConfidence, got.Confidence)
+		}
+
+		var total int64
+		for _, count := range got.Histogram {
+			total += count
+		}
+		if total != 50000 {
+			t.Errorf("[histogram] want 50000 results, got %d", total)
+		}
+
+		if got.Min != 3 || got.Max != 13 {
+			t.Errorf("[range] want 3-13, got %d-%d", got.Min, got.Max)
+		}
+		if got.MeanInterval.Low > 8 || got.MeanInterval.High < 8 {
+			t.Errorf("[mean interval] want interval containing 8, got %v", got.MeanInterval)
+		}
+		if math.Abs(got.Variance-35.0/6) > 0.2 {
+			t.Errorf("[variance] want about %f, got %f", 35.0/6, got.Variance)
+		}
+
+		interval := got.ProbabilityInterval(8)
+		if interval.Low > 6.0/36 || interval.High < 6.0/36 {
+			t.Errorf("[probability interval] want interval containing %f, got %v", 6.0/36, interval)
+		}
+
+		if got.Cumulative(13) != 1 {
+			t.Errorf("[cumulative] want 1, got %f", got.Cumulative(13))
+		}
+	})
+
+	t.Run("seed reproduces results across worker counts", func(t *testing.T) {
+		one, err := Simulate(context.Background(), "dropL:4d6", 35000, SimulationOptions{Seed: 7, Workers: 1})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+		many, err := Simulate(context.Background(), "dropL:4d6", 35000, SimulationOptions{Seed: 7, Workers: 8})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if !reflect.DeepEqual(one, many) {
+			t.Errorf("want %v, got %v", one, many)
+		}
+	})
+
+	t.Run("error when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Simulate(ctx, "1d20", 1000000, SimulationOptions{})
+		if err != context.Canceled {
+			t.Errorf("want %s, got %s", context.Canceled, err)
+		}
+	})
+
+	t.Run("cancelling does not wait on the trials", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Simulate(ctx, "1d20", math.MaxInt, SimulationOptions{})
+		if err != context.Canceled {
+			t.Errorf("want %s, got %s", context.Canceled, err)
+		}
+	})
+
+	t.Run("error when trials is invalid", func(t *testing.T) {
+		_, err := Simulate(context.Background(), "1d20", 0, SimulationOptions{})
+		if err != ErrInvalidTrials {
+			t.Errorf("want %s, got %s", ErrInvalidTrials, err)
+		}
+	})
+
+	t.Run("error when expression is invalid", func(t *testing.T) {
+		_, err := Simulate(context.Background(), "hey0d20+2", 10, SimulationOptions{})
+		if err != ErrInvalidRollExpression {
+			t.Errorf("want %s, got %s", ErrInvalidRollExpression, err)
+		}
+	})
+}
+
+func TestSimulation_Distribution(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		subject, err := Simulate(context.Background(), "1d4", 1000, SimulationOptions{Seed: 1})
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		got := subject.Distribution()
+		if got.Exact() {
+			t.Error("[exact] want false, got true")
+		}
+		if !reflect.DeepEqual(got.Outcomes(), subject.Outcomes()) {
+			t.Errorf("[outcomes] want %v, got %v", subject.Outcomes(), got.Outcomes())
+		}
+		for _, value := range subject.Outcomes() {
+			if got.Probability(value) != subject.Probability(value) {
+				t.Errorf("[probability %d] want %f, got %f", value, subject.Probability(value), got.Probability(value))
+			}
+		}
+	})
+}
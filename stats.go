@@ -0,0 +1,66 @@
+package dice
+
+//Statistics summarizes the results of a roll expression.
+type Statistics struct {
+	Mean        float64
+	Variance    float64
+	StdDev      float64
+	Min         int
+	Max         int
+	Percentiles map[float64]int //keyed by the requested percent (0-100)
+	Exact       bool            //false when the values were estimated by simulation
+}
+
+//Stats returns the mean, variance, standard deviation, min, max, and any requested percentiles
+//(0-100) of the provided roll expression. The values are computed exactly when possible, expressions
+//that are too large to compute exactly are estimated by simulating many rolls instead.
+//
+//An error is returned if the expression is not a valid roll expression or a percentile is not between 0 and 100.
+func Stats(expression string, percentiles ...float64) (Statistics, error) {
+	parsed, err := parseExpression(expression)
+	if err != nil {
+		return Statistics{}, err
+	}
+
+	return statsOf(parsed.distribution(), percentiles)
+}
+
+//Stats returns the statistics for the named custom expression, see the Stats function for details.
+//Any dice referenced by the expression are included.
+func (s *Set) Stats(name string, percentiles ...float64) (Statistics, error) {
+	state := s.snapshot()
+	if state.empty() {
+		return Statistics{}, ErrEmptyDiceSet
+	}
+
+	d, err := state.distribution(name, make(map[string]bool))
+	if err != nil {
+		return Statistics{}, err
+	}
+
+	return statsOf(d, percentiles)
+}
+
+func statsOf(d *Distribution, percentiles []float64) (Statistics, error) {
+	stats := Statistics{
+		Mean:     d.Mean(),
+		Variance: d.Variance(),
+		StdDev:   d.StdDev(),
+		Min:      d.Min(),
+		Max:      d.Max(),
+		Exact:    d.Exact(),
+	}
+
+	if len(percentiles) > 0 {
+		stats.Percentiles = make(map[float64]int)
+		for _, percent := range percentiles {
+			value, err := d.Percentile(percent)
+			if err != nil {
+				return Statistics{}, err
+			}
+			stats.Percentiles[percent] = value
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,109 @@
+package dice
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_Stats(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		got, err := Stats("2d6+4", 50, 95)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if !got.Exact {
+			t.Error("[exact] want true, got false")
+		}
+		if got.Mean != 11 {
+			t.Errorf("[mean] want 11, got %f", got.Mean)
+		}
+		if math.Abs(got.Variance-35.0/6) > 1e-9 {
+			t.Errorf("[variance] want %f, got %f", 35.0/6, got.Variance)
+		}
+		if math.Abs(got.StdDev-math.Sqrt(35.0/6)) > 1e-9 {
+			t.Errorf("[stddev] want %f, got %f", math.Sqrt(35.0/6), got.StdDev)
+		}
+		if got.Min != 6 || got.Max != 16 {
+			t.Errorf("[range] want 6-16, got %d-%d", got.Min, got.Max)
+		}
+		if got.Percentiles[50] != 11 {
+			t.Errorf("[p50] want 11, got %d", got.Percentiles[50])
+		}
+		if got.Percentiles[95] != 15 {
+			t.Errorf("[p95] want 15, got %d", got.Percentiles[95])
+		}
+	})
+
+	t.Run("falls back to simulation", func(t *testing.T) {
+		got, err := Stats("dropL:dropH:100d100")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if got.Exact {
+			t.Error("[exact] want false, got true")
+		}
+		if got.Mean < 4900 || got.Mean > 5200 {
+			t.Errorf("[mean] want about 5050, got %f", got.Mean)
+		}
+	})
+
+	t.Run("large expressions are rolled fewer times", func(t *testing.T) {
+		got, err := Stats("100000d6")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if got.Exact {
+			t.Error("[exact] want false, got true")
+		}
+		if got.Mean < 349000 || got.Mean > 351000 {
+			t.Errorf("[mean] want about 350000, got %f", got.Mean)
+		}
+	})
+
+	t.Run("error when expression is invalid", func(t *testing.T) {
+		_, err := Stats("hey0d20+2")
+		if err != ErrInvalidRollExpression {
+			t.Errorf("want %s, got %s", ErrInvalidRollExpression, err)
+		}
+	})
+
+	t.Run("error when percentile is invalid", func(t *testing.T) {
+		_, err := Stats("1d20", 120)
+		if err != ErrInvalidPercentile {
+			t.Errorf("want %s, got %s", ErrInvalidPercentile, err)
+		}
+	})
+}
+
+func TestSet_Stats(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		subject := NewSet(map[string]string{"fireball": "8d6"})
+		got, err := subject.Stats("fireball")
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err)
+		}
+
+		if got.Mean != 28 {
+			t.Errorf("[mean] want 28, got %f", got.Mean)
+		}
+	})
+
+	t.Run("error when no dice in set", func(t *testing.T) {
+		subject := Set{}
+		_, err := subject.Stats("fireball")
+		if err != ErrEmptyDiceSet {
+			t.Errorf("want %s, got %s", ErrEmptyDiceSet, err)
+		}
+	})
+
+	t.Run("error when specified dice does not exist", func(t *testing.T) {
+		subject := NewSet(map[string]string{"fireball": "8d6"})
+		_, err := subject.Stats("no dice")
+		if err != ErrDiceNotFound {
+			t.Errorf("want %s, got %s", ErrDiceNotFound, err)
+		}
+	})
+}